@@ -0,0 +1,200 @@
+package alts
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Yallamaztar/go-iw4m/events"
+	"github.com/Yallamaztar/go-iw4m/models"
+	"github.com/Yallamaztar/go-iw4m/names"
+	"github.com/Yallamaztar/go-iw4m/player"
+	"github.com/Yallamaztar/go-iw4m/server"
+	"github.com/Yallamaztar/go-iw4m/wrapper"
+)
+
+const (
+	DefaultMinConfidence = 0.5
+	// DefaultCheckTTL is how long a joining client isn't checked again
+	DefaultCheckTTL = time.Hour
+)
+
+type Warning struct {
+	Player models.Player `json:"player"`
+	Alt    Link          `json:"alt"`
+	Ban    string        `json:"ban"`
+}
+
+func (w Warning) String() string {
+	return fmt.Sprintf("%s (#%s) is a likely alt (%.0f%%) of banned %s (#%s): %s",
+		w.Player.Name, w.Player.XUID, w.Alt.Confidence*100, w.Alt.Name, w.Alt.ClientID, w.Ban)
+}
+
+type Detector struct {
+	Server        *server.Server
+	Player        *player.Player
+	Graph         *Graph
+	MinConfidence float64
+	// CheckTTL keeps Attach from checking a client again on every rejoin
+	// or map change
+	CheckTTL time.Duration
+
+	mu      sync.Mutex
+	checked map[string]time.Time
+}
+
+// Constructor to create Detector from IW4MWrapper instance
+func NewDetector(w *wrapper.IW4MWrapper) *Detector {
+	return &Detector{
+		Server:        server.NewServer(w),
+		Player:        player.NewPlayer(w),
+		Graph:         NewGraph(),
+		MinConfidence: DefaultMinConfidence,
+		CheckTTL:      DefaultCheckTTL,
+	}
+}
+
+// IngestRecent adds the given number of RecentClients pages to the graph
+func (d *Detector) IngestRecent(pages int) error {
+	for i := 0; i < pages; i++ {
+		clients, err := d.Server.RecentClients(i * 20)
+		if err != nil {
+			return err
+		}
+		if len(clients) == 0 {
+			break
+		}
+		d.Graph.AddRecentClients(clients)
+	}
+	return nil
+}
+
+// Ingest adds a client's profile to the graph and searches the client
+// database for everyone else seen on the same IPs or with the same aliases
+func (d *Detector) Ingest(ctx context.Context, clientID string) error {
+	profile, err := d.Player.Profile(clientID)
+	if err != nil {
+		return err
+	}
+	d.Graph.AddProfile(profile)
+
+	for _, ip := range d.Graph.IPs(clientID) {
+		clients, _, err := d.Server.FindPlayers(ctx, server.FindOptions{IP: ip})
+		if err != nil {
+			continue
+		}
+		for _, c := range clients {
+			d.Graph.SetName(c.ClientID, c.Name)
+			d.Graph.AddIP(c.ClientID, ip)
+		}
+	}
+
+	aliases := append([]models.Alias{{Name: profile.Name}}, profile.Aliases...)
+	for _, a := range aliases {
+		target := names.Normalize(a.Name, names.Options{})
+		clients, _, err := d.Server.FindPlayers(ctx, server.FindOptions{Name: target})
+		if err != nil {
+			continue
+		}
+		for _, c := range clients {
+			if names.Normalize(c.Name, names.Options{}) == target {
+				d.Graph.AddAlias(c.ClientID, c.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// Report ingests clientID and returns its likely alts
+func (d *Detector) Report(ctx context.Context, clientID string) ([]Link, error) {
+	if err := d.Ingest(ctx, clientID); err != nil {
+		return nil, err
+	}
+	return d.Graph.Alts(clientID, d.MinConfidence), nil
+}
+
+// banned describes a client's active ban, or "" when there is none
+func (d *Detector) banned(clientID string) string {
+	if info, err := d.Player.ClientInfo(clientID); err == nil && info.Role == models.RoleBanned {
+		return "banned"
+	}
+	penalties, err := d.Player.Penalties(clientID)
+	if err != nil {
+		return ""
+	}
+	for _, p := range penalties {
+		if p.Active && (p.Type == models.PenaltyBan || p.Type == models.PenaltyTempBan) {
+			return fmt.Sprintf("%s: %s", p.Type, p.Reason)
+		}
+	}
+	return ""
+}
+
+// Check reports every likely alt of a connecting player that is banned
+func (d *Detector) Check(ctx context.Context, p models.Player) ([]Warning, error) {
+	links, err := d.Report(ctx, p.XUID)
+	if err != nil {
+		return nil, err
+	}
+
+	var warnings []Warning
+	for _, l := range links {
+		if ban := d.banned(l.ClientID); ban != "" {
+			warnings = append(warnings, Warning{Player: p, Alt: l, Ban: ban})
+		}
+	}
+	return warnings, nil
+}
+
+// Attach checks joining players and calls warn for each banned alt. The
+// lookups run off the bus goroutine, and a client is checked at most once
+// per CheckTTL; warn and onError may be called concurrently
+func (d *Detector) Attach(bus *events.Bus, warn func(Warning), onError func(error)) *events.Subscription {
+	return events.Handle(bus, func(e events.JoinEvent) {
+		if e.Player.XUID == "" || !d.due(e.Player.XUID, time.Now()) {
+			return
+		}
+		go func() {
+			warnings, err := d.Check(context.Background(), e.Player)
+			if err != nil {
+				// let the next join try again
+				d.mu.Lock()
+				delete(d.checked, e.Player.XUID)
+				d.mu.Unlock()
+				if onError != nil {
+					onError(err)
+				}
+				return
+			}
+			for _, w := range warnings {
+				warn(w)
+			}
+		}()
+	})
+}
+
+// due reports whether clientID should be checked now and, if so, marks it
+// checked. Expired marks are dropped on the way
+func (d *Detector) due(clientID string, now time.Time) bool {
+	ttl := d.CheckTTL
+	if ttl <= 0 {
+		ttl = DefaultCheckTTL
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if at, ok := d.checked[clientID]; ok && now.Sub(at) < ttl {
+		return false
+	}
+	if d.checked == nil {
+		d.checked = make(map[string]time.Time)
+	}
+	for id, at := range d.checked {
+		if now.Sub(at) >= ttl {
+			delete(d.checked, id)
+		}
+	}
+	d.checked[clientID] = now
+	return true
+}
@@ -0,0 +1,27 @@
+package alts
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDue(t *testing.T) {
+	d := &Detector{CheckTTL: time.Hour}
+	now := time.Now()
+
+	if !d.due("1", now) {
+		t.Fatal("first join not checked")
+	}
+	if d.due("1", now.Add(10*time.Minute)) {
+		t.Error("rejoin within the TTL checked again")
+	}
+	if !d.due("2", now.Add(10*time.Minute)) {
+		t.Error("another client not checked")
+	}
+	if !d.due("1", now.Add(time.Hour)) {
+		t.Error("rejoin after the TTL not checked")
+	}
+	if !d.due("3", now.Add(2*time.Hour)) || len(d.checked) != 1 {
+		t.Errorf("%d clients remembered; want expired ones dropped", len(d.checked))
+	}
+}
@@ -0,0 +1,202 @@
+package alts
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/Yallamaztar/go-iw4m/models"
+	"github.com/Yallamaztar/go-iw4m/names"
+	"github.com/Yallamaztar/go-iw4m/utils"
+)
+
+const (
+	ipWeight    = 0.7
+	aliasWeight = 0.4
+)
+
+// genericNames are default or placeholder names shared by unrelated players
+var genericNames = map[string]bool{
+	"unknown soldier": true,
+	"player":          true,
+	"unnamed player":  true,
+	"newplayer":       true,
+}
+
+type set map[string]struct{}
+
+func (s set) add(v string) { s[v] = struct{}{} }
+
+func (s set) sorted() []string {
+	out := make([]string, 0, len(s))
+	for v := range s {
+		out = append(out, v)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Graph links clients that share IP addresses or aliases
+type Graph struct {
+	mu            sync.RWMutex
+	ips           map[string]set
+	aliases       map[string]set
+	clientIPs     map[string]set
+	clientAliases map[string]set
+	names         map[string]string
+}
+
+// Constructor to create an empty Graph
+func NewGraph() *Graph {
+	return &Graph{
+		ips:           make(map[string]set),
+		aliases:       make(map[string]set),
+		clientIPs:     make(map[string]set),
+		clientAliases: make(map[string]set),
+		names:         make(map[string]string),
+	}
+}
+
+func link(index map[string]set, reverse map[string]set, key, clientID string) {
+	if index[key] == nil {
+		index[key] = make(set)
+	}
+	index[key].add(clientID)
+	if reverse[clientID] == nil {
+		reverse[clientID] = make(set)
+	}
+	reverse[clientID].add(key)
+}
+
+func (g *Graph) AddIP(clientID, ip string) {
+	ip = strings.TrimSpace(ip)
+	if clientID == "" || ip == "" {
+		return
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	link(g.ips, g.clientIPs, ip, clientID)
+}
+
+func (g *Graph) AddAlias(clientID, name string) {
+	alias := names.Normalize(name, names.Options{})
+	if clientID == "" || alias == "" || genericNames[alias] {
+		return
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	link(g.aliases, g.clientAliases, alias, clientID)
+	if _, ok := g.names[clientID]; !ok {
+		g.names[clientID] = utils.StripColorCodes(name)
+	}
+}
+
+func (g *Graph) SetName(clientID, name string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.names[clientID] = utils.StripColorCodes(name)
+}
+
+func (g *Graph) AddRecentClients(clients []models.RecentClient) {
+	for _, c := range clients {
+		id := utils.ClientIDFromLink(c.Link)
+		g.AddIP(id, c.IPAddress)
+		g.AddAlias(id, c.Name)
+	}
+}
+
+func (g *Graph) AddProfile(p *models.Profile) {
+	g.SetName(p.ClientID, p.Name)
+	g.AddAlias(p.ClientID, p.Name)
+	for _, a := range p.Aliases {
+		g.AddAlias(p.ClientID, a.Name)
+	}
+	for _, ip := range p.IPs {
+		g.AddIP(p.ClientID, ip.Address)
+	}
+}
+
+// IPs returns the addresses known for a client
+func (g *Graph) IPs(clientID string) []string {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.clientIPs[clientID].sorted()
+}
+
+// Aliases returns the normalized aliases known for a client
+func (g *Graph) Aliases(clientID string) []string {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.clientAliases[clientID].sorted()
+}
+
+type Link struct {
+	ClientID      string   `json:"client_id"`
+	Name          string   `json:"name"`
+	SharedIPs     []string `json:"shared_ips"`
+	SharedAliases []string `json:"shared_aliases"`
+	Confidence    float64  `json:"confidence"`
+}
+
+// Alts returns clients linked to clientID with at least minConfidence,
+// most likely first. Evidence is combined as 1 - Π(1 - w); an IP or alias
+// shared by many clients (NAT, common names) contributes less
+func (g *Graph) Alts(clientID string, minConfidence float64) []Link {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	links := make(map[string]*Link)
+	miss := make(map[string]float64)
+	get := func(id string) *Link {
+		l, ok := links[id]
+		if !ok {
+			l = &Link{ClientID: id, Name: g.names[id]}
+			links[id] = l
+			miss[id] = 1
+		}
+		return l
+	}
+
+	for ip := range g.clientIPs[clientID] {
+		others := g.ips[ip]
+		w := ipWeight / float64(max(1, len(others)-1))
+		for id := range others {
+			if id == clientID {
+				continue
+			}
+			l := get(id)
+			l.SharedIPs = append(l.SharedIPs, ip)
+			miss[id] *= 1 - w
+		}
+	}
+	for alias := range g.clientAliases[clientID] {
+		others := g.aliases[alias]
+		w := aliasWeight / float64(max(1, len(others)-1))
+		for id := range others {
+			if id == clientID {
+				continue
+			}
+			l := get(id)
+			l.SharedAliases = append(l.SharedAliases, alias)
+			miss[id] *= 1 - w
+		}
+	}
+
+	var out []Link
+	for id, l := range links {
+		l.Confidence = 1 - miss[id]
+		if l.Confidence < minConfidence {
+			continue
+		}
+		sort.Strings(l.SharedIPs)
+		sort.Strings(l.SharedAliases)
+		out = append(out, *l)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Confidence != out[j].Confidence {
+			return out[i].Confidence > out[j].Confidence
+		}
+		return out[i].ClientID < out[j].ClientID
+	})
+	return out
+}
@@ -0,0 +1,414 @@
+package anticheat
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Yallamaztar/go-iw4m/commands"
+	"github.com/Yallamaztar/go-iw4m/events"
+	"github.com/Yallamaztar/go-iw4m/models"
+	"github.com/Yallamaztar/go-iw4m/player"
+	"github.com/Yallamaztar/go-iw4m/server"
+	"github.com/Yallamaztar/go-iw4m/stats"
+	"github.com/Yallamaztar/go-iw4m/utils"
+	"github.com/Yallamaztar/go-iw4m/wrapper"
+)
+
+const (
+	DefaultThreshold   = 2.5
+	DefaultMinHits     = 200
+	DefaultTopCount    = 25
+	DefaultBaselineTTL = time.Hour
+	// sampleWorkers is how many clients RefreshBaseline samples at once
+	sampleWorkers = 4
+)
+
+// Sample is the set of metrics compared against the baseline
+type Sample struct {
+	ClientID      string  `json:"client_id"`
+	Name          string  `json:"name"`
+	Hits          int     `json:"hits"`
+	HeadNeckRatio float64 `json:"head_neck_ratio"`
+	Accuracy      float64 `json:"accuracy"`
+	// Weapons holds kills per hit for each weapon with enough hits
+	Weapons map[string]float64 `json:"weapons"`
+}
+
+type Stat struct {
+	Mean   float64 `json:"mean"`
+	StdDev float64 `json:"stddev"`
+	N      int     `json:"n"`
+}
+
+func (s Stat) Z(v float64) float64 {
+	if s.N < 2 || s.StdDev == 0 {
+		return 0
+	}
+	return (v - s.Mean) / s.StdDev
+}
+
+func newStat(values []float64) Stat {
+	if len(values) == 0 {
+		return Stat{}
+	}
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+
+	var sq float64
+	for _, v := range values {
+		sq += (v - mean) * (v - mean)
+	}
+	std := 0.0
+	if len(values) > 1 {
+		std = math.Sqrt(sq / float64(len(values)-1))
+	}
+	return Stat{Mean: mean, StdDev: std, N: len(values)}
+}
+
+type Baseline struct {
+	HeadNeck Stat            `json:"head_neck"`
+	Accuracy Stat            `json:"accuracy"`
+	Weapons  map[string]Stat `json:"weapons"`
+	Built    time.Time       `json:"built"`
+	// Samples are the players the baseline was built from
+	Samples []Sample `json:"samples,omitempty"`
+}
+
+// BuildBaseline aggregates samples into per-metric means and deviations
+func BuildBaseline(samples []Sample) *Baseline {
+	var headNeck, accuracy []float64
+	weapons := make(map[string][]float64)
+	for _, s := range samples {
+		headNeck = append(headNeck, s.HeadNeckRatio)
+		if s.Accuracy > 0 {
+			accuracy = append(accuracy, s.Accuracy)
+		}
+		for w, v := range s.Weapons {
+			weapons[w] = append(weapons[w], v)
+		}
+	}
+
+	b := &Baseline{
+		HeadNeck: newStat(headNeck),
+		Accuracy: newStat(accuracy),
+		Weapons:  make(map[string]Stat, len(weapons)),
+		Built:    time.Now(),
+		Samples:  samples,
+	}
+	for w, values := range weapons {
+		b.Weapons[w] = newStat(values)
+	}
+	return b
+}
+
+// Without returns the baseline rebuilt without clientID's sample, so a
+// player is never compared against their own numbers. b is returned as is
+// when the client isn't part of it
+func (b *Baseline) Without(clientID string) *Baseline {
+	rest := make([]Sample, 0, len(b.Samples))
+	for _, s := range b.Samples {
+		if s.ClientID != clientID {
+			rest = append(rest, s)
+		}
+	}
+	if len(rest) == len(b.Samples) {
+		return b
+	}
+	without := BuildBaseline(rest)
+	without.Built = b.Built
+	return without
+}
+
+type Finding struct {
+	Metric string  `json:"metric"`
+	Value  float64 `json:"value"`
+	Mean   float64 `json:"mean"`
+	StdDev float64 `json:"stddev"`
+	Z      float64 `json:"z"`
+	Reason string  `json:"reason"`
+}
+
+type Report struct {
+	ClientID   string    `json:"client_id"`
+	Name       string    `json:"name"`
+	Hits       int       `json:"hits"`
+	Score      float64   `json:"score"`
+	Suspicious bool      `json:"suspicious"`
+	Findings   []Finding `json:"findings"`
+	Reasons    []string  `json:"reasons"`
+}
+
+type Analyzer struct {
+	Player *player.Player
+	Server *server.Server
+	// Threshold is the z-score above which a metric is reported
+	Threshold   float64
+	MinHits     int
+	TopCount    int
+	BaselineTTL time.Duration
+
+	mu       sync.Mutex
+	baseline *Baseline
+	refresh  *refresh
+}
+
+// refresh is a baseline build in progress that concurrent callers wait on
+type refresh struct {
+	done     chan struct{}
+	baseline *Baseline
+	err      error
+}
+
+// Constructor to create Analyzer from IW4MWrapper instance
+func NewAnalyzer(w *wrapper.IW4MWrapper) *Analyzer {
+	return &Analyzer{
+		Player:      player.NewPlayer(w),
+		Server:      server.NewServer(w),
+		Threshold:   DefaultThreshold,
+		MinHits:     DefaultMinHits,
+		TopCount:    DefaultTopCount,
+		BaselineTTL: DefaultBaselineTTL,
+	}
+}
+
+// Sample fetches a client's advanced stats and extracts the metrics
+func (a *Analyzer) Sample(clientID string) (Sample, error) {
+	advanced, err := a.Player.AdvancedStats(clientID)
+	if err != nil {
+		return Sample{}, err
+	}
+	view := stats.FromAdvanced(advanced)
+
+	s := Sample{
+		ClientID:      clientID,
+		Name:          view.Name,
+		Hits:          view.TotalHits(),
+		HeadNeckRatio: view.LocationRatio("head", "neck"),
+		Accuracy:      view.Accuracy(),
+		Weapons:       make(map[string]float64),
+	}
+	for _, w := range view.AllWeapons() {
+		if w.Hits >= a.MinHits/4 && w.Hits > 0 {
+			s.Weapons[w.Weapon] = w.KillsPerHit()
+		}
+	}
+	return s, nil
+}
+
+// RefreshBaseline samples the top players and the current server
+// population. Callers arriving while a refresh runs share its result
+func (a *Analyzer) RefreshBaseline() (*Baseline, error) {
+	a.mu.Lock()
+	if r := a.refresh; r != nil {
+		a.mu.Unlock()
+		<-r.done
+		return r.baseline, r.err
+	}
+	r := &refresh{done: make(chan struct{})}
+	a.refresh = r
+	a.mu.Unlock()
+
+	r.baseline, r.err = a.buildBaseline()
+
+	a.mu.Lock()
+	if r.err == nil {
+		a.baseline = r.baseline
+	}
+	a.refresh = nil
+	a.mu.Unlock()
+	close(r.done)
+	return r.baseline, r.err
+}
+
+func (a *Analyzer) buildBaseline() (*Baseline, error) {
+	ids := make(map[string]bool)
+
+	top, err := a.Server.TopPlayers(a.TopCount)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range top {
+		if id := utils.ClientIDFromLink(p.Link); id != "" {
+			ids[id] = true
+		}
+	}
+	if players, err := a.Server.GetPlayers(); err == nil {
+		for _, p := range players {
+			if p.XUID != "" {
+				ids[p.XUID] = true
+			}
+		}
+	}
+
+	queue := make(chan string)
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		samples []Sample
+	)
+	for range sampleWorkers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for id := range queue {
+				s, err := a.Sample(id)
+				if err != nil || s.Hits < a.MinHits {
+					continue
+				}
+				mu.Lock()
+				samples = append(samples, s)
+				mu.Unlock()
+			}
+		}()
+	}
+	for id := range ids {
+		queue <- id
+	}
+	close(queue)
+	wg.Wait()
+
+	if len(samples) < 2 {
+		return nil, fmt.Errorf("not enough players with %d+ hits to build a baseline", a.MinHits)
+	}
+	sort.Slice(samples, func(i, j int) bool { return samples[i].ClientID < samples[j].ClientID })
+	return BuildBaseline(samples), nil
+}
+
+// Baseline returns the current baseline, rebuilding it once it is older
+// than BaselineTTL
+func (a *Analyzer) Baseline() (*Baseline, error) {
+	a.mu.Lock()
+	b := a.baseline
+	a.mu.Unlock()
+
+	if b != nil && time.Since(b.Built) < a.BaselineTTL {
+		return b, nil
+	}
+	return a.RefreshBaseline()
+}
+
+// SetBaseline replaces the baseline, e.g. with one loaded from disk
+func (a *Analyzer) SetBaseline(b *Baseline) {
+	a.mu.Lock()
+	a.baseline = b
+	a.mu.Unlock()
+}
+
+// Analyze compares a client's metrics against the baseline
+func (a *Analyzer) Analyze(clientID string) (*Report, error) {
+	b, err := a.Baseline()
+	if err != nil {
+		return nil, err
+	}
+	s, err := a.Sample(clientID)
+	if err != nil {
+		return nil, err
+	}
+	return a.Compare(s, b), nil
+}
+
+// Compare scores a sample against b, leaving the sample's own player out
+// of it, without any network access
+func (a *Analyzer) Compare(s Sample, b *Baseline) *Report {
+	r := &Report{ClientID: s.ClientID, Name: s.Name, Hits: s.Hits}
+	if s.Hits < a.MinHits {
+		r.Reasons = append(r.Reasons, fmt.Sprintf("only %d hits recorded, not enough data", s.Hits))
+		return r
+	}
+	b = b.Without(s.ClientID)
+
+	percent := func(v float64) string { return fmt.Sprintf("%.1f%%", v*100) }
+	ratio := func(v float64) string { return fmt.Sprintf("%.2f", v) }
+
+	check := func(metric string, value float64, stat Stat, label string, format func(float64) string) {
+		z := stat.Z(value)
+		r.Score = max(r.Score, z)
+		if z < a.Threshold {
+			return
+		}
+		reason := fmt.Sprintf("%s %s vs %s average (z=%.1f)", label, format(value), format(stat.Mean), z)
+		r.Findings = append(r.Findings, Finding{
+			Metric: metric,
+			Value:  value,
+			Mean:   stat.Mean,
+			StdDev: stat.StdDev,
+			Z:      z,
+			Reason: reason,
+		})
+	}
+
+	check("head_neck_ratio", s.HeadNeckRatio, b.HeadNeck, "head/neck hits", percent)
+	if s.Accuracy > 0 {
+		check("accuracy", s.Accuracy, b.Accuracy, "accuracy", percent)
+	}
+	for _, w := range sortedWeapons(s.Weapons) {
+		if stat, ok := b.Weapons[w]; ok {
+			check("weapon:"+w, s.Weapons[w], stat, w+" kills per hit", ratio)
+		}
+	}
+
+	sort.SliceStable(r.Findings, func(i, j int) bool { return r.Findings[i].Z > r.Findings[j].Z })
+	for _, f := range r.Findings {
+		r.Reasons = append(r.Reasons, f.Reason)
+	}
+	r.Suspicious = len(r.Findings) > 0
+	return r
+}
+
+func sortedWeapons(m map[string]float64) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Attach analyzes every joining player and calls notify for suspicious
+// ones. Analysis runs off the bus goroutine, so a slow baseline refresh
+// doesn't hold up other subscribers; notify and onError may be called
+// concurrently
+func (a *Analyzer) Attach(bus *events.Bus, notify func(*Report), onError func(error)) *events.Subscription {
+	return events.Handle(bus, func(e events.JoinEvent) {
+		if e.Player.XUID == "" {
+			return
+		}
+		go a.join(e.Player, notify, onError)
+	})
+}
+
+func (a *Analyzer) join(p models.Player, notify func(*Report), onError func(error)) {
+	r, err := a.Analyze(p.XUID)
+	if err != nil {
+		if onError != nil {
+			onError(err)
+		}
+		return
+	}
+	if r.Name == "" {
+		r.Name = p.Name
+	}
+	if r.Suspicious && notify != nil {
+		notify(r)
+	}
+}
+
+// TellStaff returns a notify func that privately messages every online
+// player at or above minRole, as listed by players
+func TellStaff(players *server.PlayerCache, minRole models.Role) func(*Report) {
+	return func(r *Report) {
+		msg := fmt.Sprintf("[anticheat] %s (#%s): %s", r.Name, r.ClientID, strings.Join(r.Reasons, "; "))
+		for _, p := range players.Players() {
+			if models.PlayerRole(p.Role) >= minRole && p.XUID != "" {
+				players.Server.SendCommand(commands.Tell(commands.Target(p.Name, p.XUID), msg))
+			}
+		}
+	}
+}
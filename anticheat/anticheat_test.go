@@ -0,0 +1,130 @@
+package anticheat
+
+import (
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Yallamaztar/go-iw4m/wrapper"
+)
+
+func TestNewStat(t *testing.T) {
+	tests := []struct {
+		values []float64
+		want   Stat
+	}{
+		{nil, Stat{}},
+		{[]float64{5}, Stat{Mean: 5, N: 1}},
+		{[]float64{2, 4, 4, 4, 5, 5, 7, 9}, Stat{Mean: 5, StdDev: math.Sqrt(32.0 / 7), N: 8}},
+	}
+	for _, tt := range tests {
+		got := newStat(tt.values)
+		if got.N != tt.want.N || math.Abs(got.Mean-tt.want.Mean) > 1e-9 || math.Abs(got.StdDev-tt.want.StdDev) > 1e-9 {
+			t.Errorf("newStat(%v) = %+v; want %+v", tt.values, got, tt.want)
+		}
+	}
+}
+
+func TestStatZ(t *testing.T) {
+	s := Stat{Mean: 0.2, StdDev: 0.05, N: 30}
+	tests := []struct {
+		stat Stat
+		v    float64
+		want float64
+	}{
+		{s, 0.2, 0},
+		{s, 0.35, 3},
+		{s, 0.1, -2},
+		{Stat{Mean: 1, StdDev: 0, N: 10}, 5, 0},
+		{Stat{Mean: 1, StdDev: 1, N: 1}, 5, 0},
+	}
+	for _, tt := range tests {
+		if got := tt.stat.Z(tt.v); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("%+v.Z(%v) = %v; want %v", tt.stat, tt.v, got, tt.want)
+		}
+	}
+}
+
+func sample(id string, headNeck, kph float64) Sample {
+	return Sample{ClientID: id, Hits: 1000, HeadNeckRatio: headNeck, Weapons: map[string]float64{"ak47": kph}}
+}
+
+func TestCompareExcludesSuspect(t *testing.T) {
+	a := &Analyzer{Threshold: DefaultThreshold, MinHits: DefaultMinHits}
+	samples := []Sample{
+		sample("1", 0.20, 0.30), sample("2", 0.22, 0.32), sample("3", 0.18, 0.28),
+		sample("4", 0.21, 0.31), sample("5", 0.19, 0.29),
+	}
+	suspect := sample("9", 0.60, 0.90)
+	b := BuildBaseline(append(samples, suspect))
+
+	// with the suspect in it the baseline is wide enough to hide them
+	if z := b.HeadNeck.Z(suspect.HeadNeckRatio); z >= DefaultThreshold {
+		t.Fatalf("suspect stands out of a baseline including them (z=%.1f); test needs a tighter setup", z)
+	}
+
+	r := a.Compare(suspect, b)
+	if !r.Suspicious {
+		t.Fatalf("suspect not flagged: %+v", r)
+	}
+	if b.Without("9").HeadNeck.N != len(samples) {
+		t.Error("Without kept the suspect's sample")
+	}
+	if b.Without("nobody") != b {
+		t.Error("Without rebuilt a baseline the client isn't part of")
+	}
+
+	var weapon string
+	for _, f := range r.Findings {
+		if f.Metric == "weapon:ak47" {
+			weapon = f.Reason
+		}
+	}
+	if !strings.HasPrefix(weapon, "ak47 kills per hit 0.90 vs 0.30 average") {
+		t.Errorf("weapon reason = %q; want a ratio, not a percentage", weapon)
+	}
+}
+
+func TestRefreshBaselineShared(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		<-release
+	}))
+	defer ts.Close()
+
+	a := NewAnalyzer(&wrapper.IW4MWrapper{BaseURL: ts.URL, Client: ts.Client()})
+	var wg sync.WaitGroup
+	errs := make(chan error, 3)
+	for range 3 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := a.RefreshBaseline()
+			errs <- err
+		}()
+	}
+	for calls.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	close(errs)
+
+	// one refresh: the top players and the player list, nothing to sample
+	if n := calls.Load(); n != 2 {
+		t.Errorf("%d requests for three concurrent refreshes; want 2", n)
+	}
+	for err := range errs {
+		if err == nil {
+			t.Error("refresh without players succeeded")
+		}
+	}
+}
@@ -0,0 +1,266 @@
+package auditstats
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Yallamaztar/go-iw4m/models"
+	"github.com/Yallamaztar/go-iw4m/names"
+	"github.com/Yallamaztar/go-iw4m/server"
+	"github.com/Yallamaztar/go-iw4m/stats"
+	"github.com/Yallamaztar/go-iw4m/utils"
+	"github.com/Yallamaztar/go-iw4m/wrapper"
+)
+
+const (
+	DefaultTopTargets = 5
+	DefaultMaxPages   = 200
+)
+
+// Entry is an audit log entry with its time parsed. When the time can't
+// be parsed At is zero and TimeError says why
+type Entry struct {
+	models.AuditLog
+	At        time.Time `json:"at"`
+	TimeError string    `json:"time_error,omitempty"`
+}
+
+// Unparsed is an entry left out of the report because its time couldn't
+// be read
+type Unparsed struct {
+	Type   string `json:"type"`
+	Origin string `json:"origin"`
+	Time   string `json:"time"`
+	Error  string `json:"error"`
+}
+
+type Count struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+type AdminReport struct {
+	Name          string         `json:"name"`
+	ClientID      string         `json:"client_id,omitempty"`
+	Role          string         `json:"role,omitempty"`
+	Total         int            `json:"total"`
+	Actions       map[string]int `json:"actions"`
+	TopTargets    []Count        `json:"top_targets"`
+	Hours         [24]int        `json:"hours"`
+	FirstAction   time.Time      `json:"first_action"`
+	LastAction    time.Time      `json:"last_action"`
+	LastConnected string         `json:"last_connected,omitempty"`
+}
+
+// InactiveAdmin is a staff member with no audit entries in the range
+type InactiveAdmin struct {
+	Name          string `json:"name"`
+	Role          string `json:"role"`
+	LastConnected string `json:"last_connected"`
+	// ConnectedInRange is false when the admin also hasn't played in the range
+	ConnectedInRange bool `json:"connected_in_range"`
+}
+
+type Report struct {
+	From      time.Time       `json:"from"`
+	To        time.Time       `json:"to"`
+	Generated time.Time       `json:"generated"`
+	Total     int             `json:"total"`
+	Admins    []AdminReport   `json:"admins"`
+	Inactive  []InactiveAdmin `json:"inactive"`
+	// Unparsed lists entries whose time could not be read
+	Unparsed []Unparsed `json:"unparsed"`
+}
+
+type Options struct {
+	From time.Time
+	To   time.Time
+	// TopTargets limits the most-targeted players listed per admin
+	TopTargets int
+	// MaxPages stops paging through very large audit logs
+	MaxPages int
+}
+
+type Analyzer struct {
+	Server *server.Server
+	// Now resolves relative webfront times; defaults to time.Now
+	Now func() time.Time
+}
+
+// Constructor to create Analyzer from IW4MWrapper instance
+func NewAnalyzer(w *wrapper.IW4MWrapper) *Analyzer {
+	return &Analyzer{Server: server.NewServer(w), Now: time.Now}
+}
+
+func (o *Options) defaults(now time.Time) {
+	if o.To.IsZero() {
+		o.To = now
+	}
+	if o.From.IsZero() {
+		o.From = o.To.AddDate(0, -1, 0)
+	}
+	if o.TopTargets <= 0 {
+		o.TopTargets = DefaultTopTargets
+	}
+	if o.MaxPages <= 0 {
+		o.MaxPages = DefaultMaxPages
+	}
+}
+
+// Collect pages through the audit log, newest first, until it passes
+// from. Entries whose time can't be parsed are returned with a zero At
+// and the parse error, and never end the paging
+func (a *Analyzer) Collect(ctx context.Context, from time.Time, maxPages int) ([]Entry, error) {
+	if maxPages <= 0 {
+		maxPages = DefaultMaxPages
+	}
+	now := a.Now()
+
+	var entries []Entry
+	offset := 0
+	for page := 0; page < maxPages; page++ {
+		p, err := a.Server.AuditLogPage(ctx, offset)
+		if err != nil {
+			return entries, err
+		}
+
+		older := false
+		for _, log := range p.Entries {
+			at, err := stats.ParseTimestamp(log.Time, now)
+			if err != nil {
+				entries = append(entries, Entry{AuditLog: log, TimeError: err.Error()})
+				continue
+			}
+			entries = append(entries, Entry{AuditLog: log, At: at})
+			if at.Before(from) {
+				older = true
+			}
+		}
+		if !p.HasMore || older {
+			break
+		}
+		offset += len(p.Entries)
+	}
+	return entries, nil
+}
+
+// Run collects the audit log and staff list and builds the report
+func (a *Analyzer) Run(ctx context.Context, opts Options) (*Report, error) {
+	now := a.Now()
+	opts.defaults(now)
+
+	entries, err := a.Collect(ctx, opts.From, opts.MaxPages)
+	if err != nil {
+		return nil, err
+	}
+	admins, err := a.Server.Admins("all", 0)
+	if err != nil {
+		return nil, err
+	}
+	return Build(entries, admins, opts, now), nil
+}
+
+func key(name string) string {
+	return names.Normalize(name, names.Options{})
+}
+
+// Build aggregates entries within opts.From-opts.To per origin and lists
+// staff from admins that have no entries in that range
+func Build(entries []Entry, admins []models.Admin, opts Options, now time.Time) *Report {
+	opts.defaults(now)
+	report := &Report{From: opts.From, To: opts.To, Generated: now}
+
+	staff := make(map[string]models.Admin, len(admins))
+	for _, a := range admins {
+		staff[key(a.Name)] = a
+	}
+
+	byAdmin := make(map[string]*AdminReport)
+	targets := make(map[string]map[string]int)
+	for _, e := range entries {
+		if e.At.IsZero() {
+			reason := e.TimeError
+			if reason == "" {
+				reason = "no time"
+			}
+			report.Unparsed = append(report.Unparsed, Unparsed{Type: e.Type, Origin: e.Origin, Time: e.Time, Error: reason})
+			continue
+		}
+		if e.At.Before(opts.From) || e.At.After(opts.To) {
+			continue
+		}
+
+		origin := strings.TrimSpace(utils.StripColorCodes(e.Origin))
+		k := key(origin)
+		r, ok := byAdmin[k]
+		if !ok {
+			r = &AdminReport{Name: origin, ClientID: utils.ClientIDFromLink(e.Href), Actions: make(map[string]int)}
+			if a, ok := staff[k]; ok {
+				r.Role = a.Role
+				r.LastConnected = a.LastConnected
+			}
+			byAdmin[k] = r
+			targets[k] = make(map[string]int)
+		}
+
+		r.Total++
+		r.Actions[e.Type]++
+		r.Hours[e.At.Hour()]++
+		if r.FirstAction.IsZero() || e.At.Before(r.FirstAction) {
+			r.FirstAction = e.At
+		}
+		if e.At.After(r.LastAction) {
+			r.LastAction = e.At
+		}
+		if target := strings.TrimSpace(utils.StripColorCodes(e.Target)); target != "" {
+			targets[k][target]++
+		}
+		report.Total++
+	}
+
+	for k, r := range byAdmin {
+		r.TopTargets = top(targets[k], opts.TopTargets)
+		report.Admins = append(report.Admins, *r)
+	}
+	sort.Slice(report.Admins, func(i, j int) bool {
+		if report.Admins[i].Total != report.Admins[j].Total {
+			return report.Admins[i].Total > report.Admins[j].Total
+		}
+		return report.Admins[i].Name < report.Admins[j].Name
+	})
+
+	for _, a := range admins {
+		if _, ok := byAdmin[key(a.Name)]; ok {
+			continue
+		}
+		inactive := InactiveAdmin{
+			Name:          utils.StripColorCodes(a.Name),
+			Role:          a.Role,
+			LastConnected: a.LastConnected,
+		}
+		if t, err := stats.ParseTimestamp(a.LastConnected, now); err == nil {
+			inactive.ConnectedInRange = !t.Before(opts.From) && !t.After(opts.To)
+		}
+		report.Inactive = append(report.Inactive, inactive)
+	}
+	return report
+}
+
+func top(counts map[string]int, n int) []Count {
+	out := make([]Count, 0, len(counts))
+	for name, c := range counts {
+		out = append(out, Count{Name: name, Count: c})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Count != out[j].Count {
+			return out[i].Count > out[j].Count
+		}
+		return out[i].Name < out[j].Name
+	})
+	if len(out) > n {
+		out = out[:n]
+	}
+	return out
+}
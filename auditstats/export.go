@@ -0,0 +1,95 @@
+package auditstats
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func (r *Report) JSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// ActionTypes returns every action type seen in the report, sorted
+func (r *Report) ActionTypes() []string {
+	seen := make(map[string]bool)
+	for _, a := range r.Admins {
+		for t := range a.Actions {
+			seen[t] = true
+		}
+	}
+	types := make([]string, 0, len(seen))
+	for t := range seen {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return types
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+// CSV writes one row per admin, active ones first: totals, a column per
+// action type, the top targets, then a column per hour of the day
+func (r *Report) CSV(w io.Writer) error {
+	types := r.ActionTypes()
+
+	header := []string{"admin", "client_id", "role", "total", "first_action", "last_action", "last_connected", "inactive"}
+	header = append(header, types...)
+	header = append(header, "top_targets")
+	for h := 0; h < 24; h++ {
+		header = append(header, fmt.Sprintf("h%02d", h))
+	}
+
+	out := csv.NewWriter(w)
+	if err := out.Write(header); err != nil {
+		return err
+	}
+
+	for _, a := range r.Admins {
+		row := []string{a.Name, a.ClientID, a.Role, strconv.Itoa(a.Total),
+			formatTime(a.FirstAction), formatTime(a.LastAction), a.LastConnected, "false"}
+		for _, t := range types {
+			row = append(row, strconv.Itoa(a.Actions[t]))
+		}
+		targets := make([]string, len(a.TopTargets))
+		for i, t := range a.TopTargets {
+			targets[i] = fmt.Sprintf("%s (%d)", t.Name, t.Count)
+		}
+		row = append(row, strings.Join(targets, "; "))
+		for _, n := range a.Hours {
+			row = append(row, strconv.Itoa(n))
+		}
+		if err := out.Write(row); err != nil {
+			return err
+		}
+	}
+
+	for _, a := range r.Inactive {
+		row := []string{a.Name, "", a.Role, "0", "", "", a.LastConnected, "true"}
+		for range types {
+			row = append(row, "0")
+		}
+		row = append(row, "")
+		for h := 0; h < 24; h++ {
+			row = append(row, "0")
+		}
+		if err := out.Write(row); err != nil {
+			return err
+		}
+	}
+
+	out.Flush()
+	return out.Error()
+}
@@ -0,0 +1,99 @@
+package automod
+
+import (
+	"strings"
+	"time"
+
+	"github.com/Yallamaztar/go-iw4m/commands"
+	"github.com/Yallamaztar/go-iw4m/events"
+	"github.com/Yallamaztar/go-iw4m/models"
+	"github.com/Yallamaztar/go-iw4m/server"
+	"github.com/Yallamaztar/go-iw4m/wrapper"
+)
+
+// Decision is one rule match and the action taken for it. In dry-run
+// mode Command is only logged
+type Decision struct {
+	Time    time.Time     `json:"time"`
+	Player  models.Player `json:"player"`
+	Message string        `json:"message"`
+	Rule    string        `json:"rule"`
+	Match   string        `json:"match"`
+	Offense int           `json:"offense"`
+	Action  string        `json:"action"`
+	Command string        `json:"command"`
+	DryRun  bool          `json:"dry_run"`
+}
+
+type Moderator struct {
+	Server  *server.Server
+	Players *server.PlayerCache
+	Config  *Config
+	// OnDecision is called for every match, including dry-run ones
+	OnDecision func(Decision)
+
+	offenses *commands.Ladder
+}
+
+// Constructor to create Moderator from IW4MWrapper instance and a compiled config
+func NewModerator(w *wrapper.IW4MWrapper, cfg *Config) *Moderator {
+	srv := server.NewServer(w)
+	return &Moderator{
+		Server:   srv,
+		Players:  server.NewPlayerCache(srv, 0),
+		Config:   cfg,
+		offenses: commands.NewLadder(cfg.resetAfter),
+	}
+}
+
+// Attach moderates chat events published on bus
+func (m *Moderator) Attach(bus *events.Bus) *events.Subscription {
+	return events.Handle(bus, func(e events.ChatEvent) { m.Check(e.Origin, e.Message) })
+}
+
+// Check runs message from origin through the rules and applies the first
+// matching rule's action. It returns nil when nothing matched or the
+// sender is exempt
+func (m *Moderator) Check(origin, message string) *Decision {
+	p, ok := m.Players.Find(origin)
+	if !ok {
+		p = models.Player{Name: origin, Role: "user"}
+	}
+	if m.Config.ExemptRole.Exempt(models.PlayerRole(p.Role)) {
+		return nil
+	}
+
+	for _, rule := range m.Config.Rules {
+		match, ok := rule.Match(message)
+		if !ok {
+			continue
+		}
+
+		key := rule.Name + "\x00" + strings.ToLower(p.Name)
+		if p.XUID != "" {
+			key = rule.Name + "\x00" + p.XUID
+		}
+		n := m.offenses.Next(key)
+		action := commands.Escalate(rule.Actions, n)
+
+		d := Decision{
+			Time:    time.Now(),
+			Player:  p,
+			Message: message,
+			Rule:    rule.Name,
+			Match:   match,
+			Offense: n,
+			Action:  action.String(),
+			Command: action.Command(commands.Target(p.Name, p.XUID), rule.Reason),
+			DryRun:  m.Config.DryRun,
+		}
+		if !d.DryRun && d.Command != "" {
+			m.Server.SendCommand(d.Command)
+		}
+		if m.OnDecision != nil {
+			m.OnDecision(d)
+		}
+		return &d
+	}
+	return nil
+}
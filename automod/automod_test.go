@@ -0,0 +1,108 @@
+package automod
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Yallamaztar/go-iw4m/commands"
+	"github.com/Yallamaztar/go-iw4m/wrapper"
+)
+
+// webfront lists Bob (client 42) and an owner on the home page and records
+// console commands
+type webfront struct {
+	mu   sync.Mutex
+	sent []string
+}
+
+func (f *webfront) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/Console/Execute" {
+		f.mu.Lock()
+		f.sent = append(f.sent, r.URL.Query().Get("command"))
+		f.mu.Unlock()
+		w.Write([]byte("[]"))
+		return
+	}
+	w.Write([]byte(`<html><body>
+		<a class="text-light-dm text-dark-lm no-decoration text-truncate ml-5 mr-5" href="/Client/Profile/42"><colorcode>Bob</colorcode></a>
+		<a class="level-color-6 no-decoration text-truncate ml-5 mr-5" href="/Client/Profile/7"><colorcode>Boss</colorcode></a>
+	</body></html>`))
+}
+
+func (f *webfront) commands() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.sent...)
+}
+
+func newTestModerator(t *testing.T, dryRun bool) (*Moderator, *webfront) {
+	t.Helper()
+	front := &webfront{}
+	ts := httptest.NewServer(front)
+	t.Cleanup(ts.Close)
+
+	cfg := &Config{DryRun: dryRun, Rules: []*Rule{{
+		Name:   "insults",
+		Words:  []string{"noob"},
+		Reason: "No insults",
+		Actions: []commands.Action{
+			{Kind: commands.ActionWarn},
+			{Kind: commands.ActionKick},
+			{Kind: commands.ActionTempBan, Duration: time.Hour},
+		},
+	}}}
+	if err := cfg.Compile(); err != nil {
+		t.Fatal(err)
+	}
+	w := &wrapper.IW4MWrapper{BaseURL: ts.URL, ServerID: "1", Client: ts.Client()}
+	return NewModerator(w, cfg), front
+}
+
+func TestModeratorEscalates(t *testing.T) {
+	m, front := newTestModerator(t, false)
+
+	if d := m.Check("Bob", "nice shot"); d != nil {
+		t.Fatalf("clean message got %+v", d)
+	}
+
+	want := []string{"!warn @42 No insults", "!kick @42 No insults", "!tempban @42 1h No insults", "!tempban @42 1h No insults"}
+	for i, command := range want {
+		d := m.Check("^2Bob", "you n00b")
+		if d == nil {
+			t.Fatalf("offense %d not matched", i+1)
+		}
+		if d.Offense != i+1 || d.Command != command || d.Player.XUID != "42" {
+			t.Errorf("offense %d = %d, %q for %q; want %q for 42", i+1, d.Offense, d.Command, d.Player.XUID, command)
+		}
+	}
+	if got := front.commands(); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("sent %q; want %q", got, want)
+	}
+
+	// staff are exempt by default
+	if d := m.Check("Boss", "noob"); d != nil {
+		t.Errorf("owner got %+v", d)
+	}
+}
+
+func TestModeratorDryRun(t *testing.T) {
+	m, front := newTestModerator(t, true)
+
+	var decisions []Decision
+	m.OnDecision = func(d Decision) { decisions = append(decisions, d) }
+
+	d := m.Check("Stranger", "noob")
+	if d == nil || !d.DryRun || d.Command != "!warn Stranger No insults" {
+		t.Fatalf("dry run = %+v; want an unsent warn for Stranger", d)
+	}
+	if len(decisions) != 1 {
+		t.Errorf("OnDecision called %d times; want 1", len(decisions))
+	}
+	if sent := front.commands(); len(sent) != 0 {
+		t.Errorf("dry run sent %q", sent)
+	}
+}
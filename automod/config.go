@@ -0,0 +1,168 @@
+package automod
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/Yallamaztar/go-iw4m/commands"
+	"github.com/Yallamaztar/go-iw4m/names"
+	"github.com/Yallamaztar/go-iw4m/stats"
+)
+
+type Rule struct {
+	Name     string   `json:"name"`
+	Words    []string `json:"words"`
+	Patterns []string `json:"patterns"`
+	Reason   string   `json:"reason"`
+	// Actions escalate per offense, e.g. ["warn", "kick", "tempban:60"]
+	Actions []commands.Action `json:"actions"`
+
+	words    []string
+	patterns []*regexp.Regexp
+}
+
+func (r *Rule) compile() error {
+	if r.Name == "" {
+		return fmt.Errorf("rule without a name")
+	}
+	for _, w := range r.Words {
+		n := Normalize(w)
+		if n == "" {
+			continue
+		}
+		r.words = append(r.words, strings.Join(Tokens(n), " "))
+	}
+	for _, p := range r.Patterns {
+		re, err := regexp.Compile("(?i)" + p)
+		if err != nil {
+			return fmt.Errorf("rule %s: %w", r.Name, err)
+		}
+		r.patterns = append(r.patterns, re)
+	}
+	if len(r.Actions) == 0 {
+		r.Actions = []commands.Action{{Kind: commands.ActionWarn}}
+	}
+	if r.Reason == "" {
+		r.Reason = r.Name
+	}
+	return nil
+}
+
+// Match reports the word or pattern that matched message, if any. Both are
+// tried against the message with color codes and look-alikes stripped and
+// with leetspeak undone; patterns also see the raw message. Words match
+// with letters held down too, so "fuuuck" matches "fuck" but "fuk" doesn't
+func (r *Rule) Match(message string) (string, bool) {
+	plain := names.Normalize(message, names.Options{})
+	leet := Normalize(message)
+
+	for _, re := range r.patterns {
+		for _, text := range []string{message, plain, leet, strings.Join(Tokens(leet), " ")} {
+			if m := re.FindString(text); m != "" {
+				return m, true
+			}
+		}
+	}
+
+	for _, text := range []string{leet, plain} {
+		tokens := Tokens(text)
+		for _, w := range r.words {
+			if containsWords(tokens, strings.Fields(w)) {
+				return w, true
+			}
+		}
+	}
+	return "", false
+}
+
+// containsWords reports whether words appear in a row in tokens
+func containsWords(tokens, words []string) bool {
+	for i := 0; i+len(words) <= len(tokens); i++ {
+		found := true
+		for j, w := range words {
+			if !stretched(tokens[i+j], w) {
+				found = false
+				break
+			}
+		}
+		if found {
+			return true
+		}
+	}
+	return false
+}
+
+// stretched reports whether token is word with any of its letters
+// repeated, e.g. "fuuck" for "fuck" or "asss" for "ass" but not "as"
+func stretched(token, word string) bool {
+	t, w := []rune(token), []rune(word)
+	i, j := 0, 0
+	for j < len(w) {
+		if i >= len(t) || t[i] != w[j] {
+			return false
+		}
+		c, inWord, inToken := w[j], 0, 0
+		for ; j < len(w) && w[j] == c; j++ {
+			inWord++
+		}
+		for ; i < len(t) && t[i] == c; i++ {
+			inToken++
+		}
+		if inToken < inWord {
+			return false
+		}
+	}
+	return i == len(t)
+}
+
+type Config struct {
+	Rules []*Rule `json:"rules"`
+	// ExemptRole exempts players at or above this IW4MAdmin role
+	ExemptRole commands.Exemption `json:"exempt_role"`
+	DryRun     bool               `json:"dry_run"`
+	// ResetAfter is how long a clean record takes to drop a player back to
+	// the first rung of every rule, e.g. "12h"
+	ResetAfter string `json:"reset_after"`
+
+	resetAfter time.Duration
+}
+
+// LoadConfig reads a JSON rules file and compiles its rules
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := cfg.Compile(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &cfg, nil
+}
+
+// Compile checks every rule and prepares its patterns and word lists
+func (c *Config) Compile() error {
+	c.resetAfter = commands.DefaultResetAfter
+	if c.ResetAfter != "" {
+		d, err := stats.ParseDuration(c.ResetAfter)
+		if err != nil {
+			return err
+		}
+		c.resetAfter = d
+	}
+
+	for _, r := range c.Rules {
+		if err := r.compile(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
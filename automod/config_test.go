@@ -0,0 +1,157 @@
+package automod
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/Yallamaztar/go-iw4m/commands"
+	"github.com/Yallamaztar/go-iw4m/models"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"^1N00B", "noob"},
+		{"h3ll0 w0rld", "hello world"},
+		{"$h!t", "sh!t"},
+		{"sooooo", "soo"},
+		{"n00b!", "noob!"},
+	}
+	for _, tt := range tests {
+		if got := Normalize(tt.in); got != tt.want {
+			t.Errorf("Normalize(%q) = %q; want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestTokens(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"b a d word", []string{"bad", "word"}},
+		{"b.a.d", []string{"bad"}},
+		{"you are a noob", []string{"you", "are", "a", "noob"}},
+	}
+	for _, tt := range tests {
+		got := Tokens(tt.in)
+		if len(got) != len(tt.want) {
+			t.Errorf("Tokens(%q) = %q; want %q", tt.in, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("Tokens(%q) = %q; want %q", tt.in, got, tt.want)
+				break
+			}
+		}
+	}
+}
+
+func TestRuleMatch(t *testing.T) {
+	rule := &Rule{
+		Name:     "insults",
+		Words:    []string{"noob"},
+		Patterns: []string{`trash\s*team`, `^go die`},
+	}
+	if err := rule.compile(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		message string
+		match   bool
+	}{
+		{"you noob", true},
+		{"n00b", true},
+		{"^1N^2O^3O^4B", true},
+		{"n o o b", true},
+		{"noobish behaviour", false},
+		{"trash team", true},
+		{"tr^1ash team", true},
+		{"tr4sh t34m", true},
+		{"^3go die", true},
+		{"let's go diego", false},
+		{"nice shot", false},
+	}
+	for _, tt := range tests {
+		if _, ok := rule.Match(tt.message); ok != tt.match {
+			t.Errorf("Match(%q) = %v; want %v", tt.message, ok, tt.match)
+		}
+	}
+}
+
+func TestRuleMatchStretched(t *testing.T) {
+	rule := &Rule{Name: "profanity", Words: []string{"fuck", "ass", "go away"}}
+	if err := rule.compile(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		message string
+		match   bool
+	}{
+		{"fuuuck", true},
+		{"FUUUUUCK off", true},
+		{"f u u u c k", true},
+		{"fuckkk", true},
+		{"fuk", false},
+		{"asssss", true},
+		{"a$$", true},
+		{"as if", false},
+		{"class", false},
+		{"gooo awaaay", true},
+		{"go", false},
+	}
+	for _, tt := range tests {
+		if _, ok := rule.Match(tt.message); ok != tt.match {
+			t.Errorf("Match(%q) = %v; want %v", tt.message, ok, tt.match)
+		}
+	}
+}
+
+func TestConfigActions(t *testing.T) {
+	var cfg Config
+	data := `{"exempt_role": "moderator", "rules": [
+		{"name": "slurs", "words": ["noob"], "actions": ["warn", " Kick ", "tempban:30", "tempban:2h"]},
+		{"name": "default", "words": ["trash"]}
+	]}`
+	if err := json.Unmarshal([]byte(data), &cfg); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.Compile(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []commands.Action{
+		{Kind: commands.ActionWarn},
+		{Kind: commands.ActionKick},
+		{Kind: commands.ActionTempBan, Duration: 30 * time.Minute},
+		{Kind: commands.ActionTempBan, Duration: 2 * time.Hour},
+	}
+	got := cfg.Rules[0].Actions
+	if len(got) != len(want) {
+		t.Fatalf("actions = %v; want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("actions[%d] = %v; want %v", i, got[i], want[i])
+		}
+	}
+	if d := cfg.Rules[1].Actions; len(d) != 1 || d[0].Kind != commands.ActionWarn {
+		t.Errorf("default actions = %v; want [warn]", d)
+	}
+	if cfg.ExemptRole != commands.ExemptFrom(models.RoleModerator) {
+		t.Errorf("exempt role = %v; want Moderator", cfg.ExemptRole)
+	}
+
+	for _, bad := range []string{`"tempban"`, `"tempban:soon"`, `"explode"`} {
+		var cfg Config
+		if err := json.Unmarshal([]byte(`{"rules": [{"name": "x", "actions": [`+bad+`]}]}`), &cfg); err == nil {
+			t.Errorf("action %s decoded, want error", bad)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package automod
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/Yallamaztar/go-iw4m/names"
+)
+
+var leet = strings.NewReplacer(
+	"0", "o", "1", "i", "3", "e", "4", "a", "5", "s", "7", "t", "8", "b", "9", "g",
+	"@", "a", "$", "s", "+", "t", "€", "e",
+)
+
+// Normalize strips color codes and look-alikes, undoes leetspeak and
+// collapses characters repeated more than twice
+func Normalize(message string) string {
+	s := names.Normalize(message, names.Options{})
+	s = leet.Replace(s)
+
+	var b strings.Builder
+	var last rune
+	repeat := 0
+	for _, r := range s {
+		if r == last {
+			repeat++
+			if repeat >= 2 {
+				continue
+			}
+		} else {
+			repeat = 0
+		}
+		last = r
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// Tokens splits normalized text into words, joining runs of single
+// characters so "b a d" and "b.a.d" style evasion reads as "bad"
+func Tokens(normalized string) []string {
+	var tokens []string
+	var run strings.Builder
+	flush := func() {
+		if run.Len() > 0 {
+			tokens = append(tokens, run.String())
+			run.Reset()
+		}
+	}
+
+	for _, t := range strings.FieldsFunc(normalized, isSeparator) {
+		if len([]rune(t)) == 1 {
+			run.WriteString(t)
+			continue
+		}
+		flush()
+		tokens = append(tokens, t)
+	}
+	flush()
+	return tokens
+}
+
+func isSeparator(r rune) bool {
+	return !(unicode.IsLetter(r) || unicode.IsDigit(r))
+}
@@ -0,0 +1,416 @@
+package bansync
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Yallamaztar/go-iw4m/commands"
+	"github.com/Yallamaztar/go-iw4m/events"
+	"github.com/Yallamaztar/go-iw4m/models"
+	"github.com/Yallamaztar/go-iw4m/player"
+	"github.com/Yallamaztar/go-iw4m/server"
+	"github.com/Yallamaztar/go-iw4m/stats"
+	"github.com/Yallamaztar/go-iw4m/wrapper"
+)
+
+const (
+	DefaultInterval = time.Minute
+	// DefaultFullScan is how often every penalty page is read, which is
+	// what notices lifted bans
+	DefaultFullScan = time.Hour
+	// DefaultMaxBackoff caps the wait before a ban that couldn't be
+	// applied on an instance is tried there again
+	DefaultMaxBackoff = 6 * time.Hour
+	maxPages          = 200
+)
+
+// BanRecord is a ban in a form that can be applied on any instance
+type BanRecord struct {
+	XUID    string             `json:"xuid"`
+	Name    string             `json:"name"`
+	Reason  string             `json:"reason"`
+	Issuer  string             `json:"issuer"`
+	Type    models.PenaltyType `json:"type"`
+	Issued  string             `json:"issued"`
+	Expires string             `json:"expires,omitempty"`
+	Source  string             `json:"source"`
+	// Generation tells apart successive bans of the same type on the same
+	// player; the ledger assigns it
+	Generation int `json:"generation"`
+}
+
+// target identifies the banned player and ban type
+func (r BanRecord) target() string {
+	return strings.ToLower(r.XUID) + "\x00" + string(r.Type)
+}
+
+// Key identifies the ban independently of where it was issued
+func (r BanRecord) Key() string {
+	return fmt.Sprintf("%s\x00%d", r.target(), r.Generation)
+}
+
+// ConflictPolicy decides what happens when the target is already penalized
+// on the destination instance
+type ConflictPolicy int
+
+const (
+	// SkipExisting leaves any existing ban on the destination alone
+	SkipExisting ConflictPolicy = iota
+	// UpgradeTempBans replaces a destination tempban with a permanent ban
+	UpgradeTempBans
+)
+
+// Instance is one IW4MAdmin host taking part in the sync
+type Instance struct {
+	Name   string
+	Server *server.Server
+	Player *player.Player
+}
+
+// Constructor to create Instance from IW4MWrapper instance
+func NewInstance(name string, w *wrapper.IW4MWrapper) *Instance {
+	return &Instance{Name: name, Server: server.NewServer(w), Player: player.NewPlayer(w)}
+}
+
+type Syncer struct {
+	Instances []*Instance
+	Ledger    *Ledger
+	// Allowlist holds XUIDs that are never synced
+	Allowlist map[string]bool
+	Conflicts ConflictPolicy
+	// TempBans also syncs temporary bans with their remaining duration
+	TempBans bool
+	Interval time.Duration
+	// FullScan is how often a pass reads every penalty page instead of
+	// stopping at bans the ledger already knows
+	FullScan time.Duration
+	// MaxBackoff caps the growing wait between attempts to apply a ban
+	// on an instance where the player isn't found or the lookup fails
+	MaxBackoff time.Duration
+	DryRun     bool
+	OnApply    func(r BanRecord, to string, command string)
+	OnError    func(instance string, err error)
+
+	trigger  chan struct{}
+	xuids    map[string]string
+	lastFull time.Time
+	retries  map[string]retry
+}
+
+// retry holds when a ban is next tried on an instance
+type retry struct {
+	at   time.Time
+	wait time.Duration
+}
+
+// Constructor to create Syncer across instances using ledger
+func NewSyncer(ledger *Ledger, instances ...*Instance) *Syncer {
+	return &Syncer{
+		Instances:  instances,
+		Ledger:     ledger,
+		Allowlist:  make(map[string]bool),
+		Interval:   DefaultInterval,
+		FullScan:   DefaultFullScan,
+		MaxBackoff: DefaultMaxBackoff,
+		trigger:    make(chan struct{}, 1),
+	}
+}
+
+func (s *Syncer) fail(instance string, err error) {
+	if s.OnError != nil {
+		s.OnError(instance, err)
+	}
+}
+
+// Bans reads every active ban on inst from its penalty list
+func (s *Syncer) Bans(inst *Instance) ([]BanRecord, error) {
+	return s.scan(inst, true)
+}
+
+// scan pages through the penalty list of inst, newest first. Unless all
+// is set it stops after a page without bans the ledger doesn't know
+func (s *Syncer) scan(inst *Instance, all bool) ([]BanRecord, error) {
+	types := []models.PenaltyType{models.PenaltyBan}
+	if s.TempBans {
+		types = append(types, models.PenaltyTempBan)
+	}
+
+	var records []BanRecord
+	for _, t := range types {
+		offset := 0
+		for n := 0; n < maxPages; n++ {
+			page, err := inst.Server.Penalties(models.PenaltyFilter{Type: t, Offset: offset})
+			if err != nil {
+				return nil, err
+			}
+
+			fresh := false
+			for _, p := range page.Penalties {
+				if !p.Active || p.OffenderID == "" {
+					continue
+				}
+				xuid := s.xuid(inst, p.OffenderID)
+				if xuid == "" {
+					continue
+				}
+				r := BanRecord{
+					XUID:    xuid,
+					Name:    p.Offender,
+					Reason:  p.Reason,
+					Issuer:  p.Punisher,
+					Type:    p.Type,
+					Issued:  p.Issued,
+					Expires: p.Expires,
+					Source:  inst.Name,
+				}
+				if !s.Ledger.Known(r) {
+					fresh = true
+				}
+				records = append(records, r)
+			}
+			if !page.HasMore || (!all && !fresh) {
+				break
+			}
+			offset += page.Count
+		}
+	}
+	return records, nil
+}
+
+// xuid resolves a client ID on inst, remembering the answer since client
+// IDs never change
+func (s *Syncer) xuid(inst *Instance, clientID string) string {
+	key := inst.Name + "\x00" + clientID
+	if x, ok := s.xuids[key]; ok {
+		return x
+	}
+
+	info, err := inst.Player.ClientInfo(clientID)
+	if err != nil || info.XUID == "" {
+		return ""
+	}
+	if s.xuids == nil {
+		s.xuids = make(map[string]string)
+	}
+	s.xuids[key] = info.XUID
+	return info.XUID
+}
+
+// destination looks up the target on inst and reports whether an
+// existing penalty already covers it
+func (s *Syncer) destination(inst *Instance, r BanRecord) (string, bool, error) {
+	clients, _, err := inst.Server.FindPlayers(context.Background(), server.FindOptions{GUID: r.XUID})
+	if err != nil {
+		return "", false, err
+	}
+
+	var clientID string
+	for _, c := range clients {
+		if strings.EqualFold(c.XUID, r.XUID) {
+			clientID = c.ClientID
+			break
+		}
+	}
+	if clientID == "" {
+		return "", false, nil
+	}
+
+	penalties, err := inst.Player.Penalties(clientID)
+	if err != nil {
+		return clientID, false, err
+	}
+	for _, p := range penalties {
+		if !p.Active {
+			continue
+		}
+		switch {
+		case p.Type == models.PenaltyBan:
+			return clientID, true, nil
+		case p.Type == models.PenaltyTempBan && (s.Conflicts == SkipExisting || r.Type == models.PenaltyTempBan):
+			return clientID, true, nil
+		}
+	}
+	return clientID, false, nil
+}
+
+func (s *Syncer) apply(to *Instance, r BanRecord) error {
+	clientID, covered, err := s.destination(to, r)
+	if err != nil {
+		return err
+	}
+	if clientID == "" {
+		// the player has never connected there; retry on a later pass
+		return nil
+	}
+	if covered {
+		return s.Ledger.MarkSkipped(r, to.Name, "already penalized")
+	}
+
+	reason := fmt.Sprintf("[%s] %s", r.Source, r.Reason)
+	cmd := commands.Ban(commands.Target(r.Name, clientID), reason)
+	if r.Type == models.PenaltyTempBan {
+		d := remaining(r.Expires)
+		if d <= 0 {
+			return s.Ledger.MarkSkipped(r, to.Name, "expired")
+		}
+		cmd = commands.TempBan(commands.Target(r.Name, clientID), d, reason)
+	}
+
+	if s.OnApply != nil {
+		s.OnApply(r, to.Name, cmd)
+	}
+	if s.DryRun {
+		return nil
+	}
+	if _, err := to.Server.ExecuteCommand(context.Background(), cmd); err != nil {
+		return err
+	}
+	return s.Ledger.MarkApplied(r, to.Name)
+}
+
+// Sync runs a single pass: collect bans from every instance and apply the
+// ones each other instance is missing
+func (s *Syncer) Sync() {
+	now := time.Now()
+	every := s.FullScan
+	if every <= 0 {
+		every = DefaultFullScan
+	}
+	full := s.lastFull.IsZero() || now.Sub(s.lastFull) >= every
+
+	bans := make(map[*Instance][]BanRecord)
+	complete := true
+	for _, from := range s.Instances {
+		records, err := s.scan(from, full)
+		if err != nil {
+			s.fail(from.Name, err)
+			complete = false
+			continue
+		}
+		for i := range records {
+			s.Ledger.Identify(&records[i])
+		}
+		bans[from] = records
+	}
+
+	// a ban no instance lists anymore was lifted or expired, so the next
+	// ban of that player is a new one. Only decide that from a full view
+	if full && complete {
+		s.lastFull = now
+		if !s.DryRun {
+			var active []BanRecord
+			for _, records := range bans {
+				active = append(active, records...)
+			}
+			if err := s.Ledger.Close(active); err != nil {
+				s.fail("ledger", err)
+			}
+		}
+	}
+
+	tried := make(map[string]bool)
+
+	for _, from := range s.Instances {
+		for _, r := range bans[from] {
+			if s.Allowlist[r.XUID] {
+				continue
+			}
+			if !s.Ledger.Seen(r, from.Name) && !s.DryRun {
+				if err := s.Ledger.MarkApplied(r, from.Name); err != nil {
+					s.fail(from.Name, err)
+				}
+			}
+
+			for _, to := range s.Instances {
+				if to == from || s.Ledger.Seen(r, to.Name) {
+					continue
+				}
+				key := r.Key() + "\x00" + to.Name
+				tried[key] = true
+				if now.Before(s.retries[key].at) {
+					continue
+				}
+				if err := s.apply(to, r); err != nil {
+					s.fail(to.Name, err)
+				}
+				if !s.Ledger.Seen(r, to.Name) {
+					s.backoff(key, now)
+				}
+			}
+		}
+	}
+
+	for key := range s.retries {
+		if !tried[key] {
+			delete(s.retries, key)
+		}
+	}
+}
+
+// backoff doubles the wait before key is tried again, starting at one
+// interval
+func (s *Syncer) backoff(key string, now time.Time) {
+	wait := s.retries[key].wait * 2
+	if wait <= 0 {
+		wait = s.Interval
+		if wait <= 0 {
+			wait = DefaultInterval
+		}
+	}
+	limit := s.MaxBackoff
+	if limit <= 0 {
+		limit = DefaultMaxBackoff
+	}
+	if wait > limit {
+		wait = limit
+	}
+	if s.retries == nil {
+		s.retries = make(map[string]retry)
+	}
+	s.retries[key] = retry{at: now.Add(wait), wait: wait}
+}
+
+// Attach triggers an immediate pass whenever a ban shows up in the audit
+// log watched by bus, instead of waiting for the next interval
+func (s *Syncer) Attach(bus *events.Bus) *events.Subscription {
+	return events.Handle(bus, func(e events.AuditEvent) {
+		if strings.Contains(strings.ToLower(e.Entry.Type), "ban") {
+			select {
+			case s.trigger <- struct{}{}:
+			default:
+			}
+		}
+	})
+}
+
+// Run syncs every Interval until ctx is cancelled
+func (s *Syncer) Run(ctx context.Context) error {
+	interval := s.Interval
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		s.Sync()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		case <-s.trigger:
+		}
+	}
+}
+
+// remaining parses "2 days remaining" style expiry text
+func remaining(expires string) time.Duration {
+	text := strings.TrimSpace(strings.NewReplacer("remaining", "", "left", "").Replace(strings.ToLower(expires)))
+	d, err := stats.ParseDuration(text)
+	if err != nil {
+		return 0
+	}
+	return d
+}
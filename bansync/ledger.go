@@ -0,0 +1,157 @@
+package bansync
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+)
+
+// Ledger remembers which bans were applied where, so restarts and
+// repeated polls never ban the same player twice
+type Ledger struct {
+	Path string
+
+	mu      sync.Mutex
+	Entries map[string]LedgerEntry `json:"entries"`
+	// Generations holds the current generation of every banned target;
+	// Open marks the ones still active on some instance
+	Generations map[string]int  `json:"generations"`
+	Open        map[string]bool `json:"open"`
+}
+
+type LedgerEntry struct {
+	Record  BanRecord            `json:"record"`
+	Applied map[string]time.Time `json:"applied"`
+	Skipped map[string]string    `json:"skipped,omitempty"`
+}
+
+// Constructor to create Ledger backed by path, loading it if it exists
+func NewLedger(path string) (*Ledger, error) {
+	l := &Ledger{Path: path, Entries: make(map[string]LedgerEntry)}
+	if path == "" {
+		return l, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return l, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, l); err != nil {
+		return nil, err
+	}
+	if l.Entries == nil {
+		l.Entries = make(map[string]LedgerEntry)
+	}
+	return l, nil
+}
+
+// Identify sets r.Generation to the ban currently open on its target
+func (l *Ledger) Identify(r *BanRecord) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.Open == nil {
+		l.Open = make(map[string]bool)
+	}
+	r.Generation = l.Generations[r.target()]
+	l.Open[r.target()] = true
+}
+
+// Known reports whether r's target already has an open ban in the ledger
+func (l *Ledger) Known(r BanRecord) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.Open[r.target()]
+}
+
+// Close moves every open target missing from active on to its next
+// generation, so a later ban of the same player is synced again
+func (l *Ledger) Close(active []BanRecord) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	still := make(map[string]bool, len(active))
+	for _, r := range active {
+		still[r.target()] = true
+	}
+	changed := false
+	for target := range l.Open {
+		if still[target] {
+			continue
+		}
+		if l.Generations == nil {
+			l.Generations = make(map[string]int)
+		}
+		l.Generations[target]++
+		delete(l.Open, target)
+		changed = true
+	}
+	if !changed {
+		return nil
+	}
+	return l.save()
+}
+
+func (l *Ledger) entry(r BanRecord) LedgerEntry {
+	e, ok := l.Entries[r.Key()]
+	if !ok {
+		e = LedgerEntry{Record: r, Applied: make(map[string]time.Time)}
+	}
+	return e
+}
+
+// Seen reports whether instance already has the ban, or skipped it
+func (l *Ledger) Seen(r BanRecord, instance string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	e, ok := l.Entries[r.Key()]
+	if !ok {
+		return false
+	}
+	if _, ok := e.Applied[instance]; ok {
+		return true
+	}
+	_, ok = e.Skipped[instance]
+	return ok
+}
+
+// MarkApplied records that instance has the ban, either because it issued
+// it or because it was synced there
+func (l *Ledger) MarkApplied(r BanRecord, instance string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	e := l.entry(r)
+	e.Applied[instance] = time.Now()
+	l.Entries[r.Key()] = e
+	return l.save()
+}
+
+func (l *Ledger) MarkSkipped(r BanRecord, instance, reason string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	e := l.entry(r)
+	if e.Skipped == nil {
+		e.Skipped = make(map[string]string)
+	}
+	e.Skipped[instance] = reason
+	l.Entries[r.Key()] = e
+	return l.save()
+}
+
+func (l *Ledger) save() error {
+	if l.Path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := l.Path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, l.Path)
+}
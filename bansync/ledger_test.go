@@ -0,0 +1,119 @@
+package bansync
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/Yallamaztar/go-iw4m/models"
+)
+
+func ban(xuid string) BanRecord {
+	return BanRecord{XUID: xuid, Name: "player", Type: models.PenaltyBan, Source: "a"}
+}
+
+func TestLedgerGenerations(t *testing.T) {
+	l, err := NewLedger("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first := ban("ABC")
+	l.Identify(&first)
+	if first.Generation != 0 {
+		t.Fatalf("first generation = %d; want 0", first.Generation)
+	}
+	if !l.Known(ban("abc")) {
+		t.Error("identified target is not known, want known regardless of xuid case")
+	}
+	if err := l.MarkApplied(first, "a"); err != nil {
+		t.Fatal(err)
+	}
+
+	// still listed: the same ban keeps its generation
+	if err := l.Close([]BanRecord{first}); err != nil {
+		t.Fatal(err)
+	}
+	again := ban("abc")
+	l.Identify(&again)
+	if again.Key() != first.Key() || !l.Seen(again, "a") {
+		t.Errorf("ban still listed got key %q; want %q and seen", again.Key(), first.Key())
+	}
+
+	// no longer listed: lifted, so the next ban is a new one
+	if err := l.Close(nil); err != nil {
+		t.Fatal(err)
+	}
+	if l.Known(first) {
+		t.Error("closed target still known")
+	}
+	next := ban("abc")
+	l.Identify(&next)
+	if next.Generation != 1 {
+		t.Fatalf("generation after close = %d; want 1", next.Generation)
+	}
+	if l.Seen(next, "a") {
+		t.Error("new ban counted as seen because of the lifted one")
+	}
+
+	// a temp ban of the same player is a separate target
+	temp := BanRecord{XUID: "abc", Type: models.PenaltyTempBan}
+	l.Identify(&temp)
+	if temp.Generation != 0 {
+		t.Errorf("tempban generation = %d; want 0", temp.Generation)
+	}
+}
+
+func TestLedgerCloseKeepsListed(t *testing.T) {
+	l, _ := NewLedger("")
+	a, b := ban("a"), ban("b")
+	l.Identify(&a)
+	l.Identify(&b)
+
+	if err := l.Close([]BanRecord{b}); err != nil {
+		t.Fatal(err)
+	}
+	if l.Known(a) || !l.Known(b) {
+		t.Errorf("known a = %v, b = %v; want false, true", l.Known(a), l.Known(b))
+	}
+	if l.Generations[b.target()] != 0 {
+		t.Errorf("listed target moved to generation %d", l.Generations[b.target()])
+	}
+}
+
+func TestLedgerPersists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ledger.json")
+	l, err := NewLedger(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	old := ban("abc")
+	l.Identify(&old)
+	if err := l.MarkApplied(old, "a"); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Close(nil); err != nil {
+		t.Fatal(err)
+	}
+	r := ban("abc")
+	l.Identify(&r)
+	if err := l.MarkSkipped(r, "b", "already penalized"); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := NewLedger(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r2 := ban("abc")
+	loaded.Identify(&r2)
+	if r2.Generation != 1 {
+		t.Errorf("reloaded generation = %d; want 1", r2.Generation)
+	}
+	if !loaded.Seen(r2, "b") || loaded.Seen(r2, "a") {
+		t.Error("reloaded ledger lost which instances have the ban")
+	}
+	if !loaded.Seen(old, "a") {
+		t.Error("reloaded ledger lost the lifted ban")
+	}
+}
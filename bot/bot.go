@@ -0,0 +1,296 @@
+package bot
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Yallamaztar/go-iw4m/commands"
+	"github.com/Yallamaztar/go-iw4m/events"
+	"github.com/Yallamaztar/go-iw4m/models"
+	"github.com/Yallamaztar/go-iw4m/server"
+	"github.com/Yallamaztar/go-iw4m/utils"
+	"github.com/Yallamaztar/go-iw4m/wrapper"
+)
+
+const DefaultPrefix = "."
+
+type HandlerFunc func(ctx *Context) error
+
+type Command struct {
+	Name        string
+	Aliases     []string
+	Usage       string
+	Description string
+	MinArgs     int
+	MinLevel    models.Role
+	// Cooldown applies per caller
+	Cooldown time.Duration
+	Handler  HandlerFunc
+}
+
+// Context is passed to command handlers
+type Context struct {
+	Bot     *Bot
+	Event   events.ChatEvent
+	Command *Command
+	Caller  models.Player
+	Level   models.Role
+	Args    []string
+}
+
+// Reply sends a private message to the caller. It fails when the caller
+// isn't online under a known client ID
+func (c *Context) Reply(format string, args ...any) error {
+	return c.Bot.Tell(c.Caller, fmt.Sprintf(format, args...))
+}
+
+// Broadcast sends a message to everyone on the server
+func (c *Context) Broadcast(format string, args ...any) {
+	c.Bot.Say(fmt.Sprintf(format, args...))
+}
+
+func (c *Context) Arg(i int) string {
+	if i < 0 || i >= len(c.Args) {
+		return ""
+	}
+	return c.Args[i]
+}
+
+func (c *Context) IntArg(i int, def int) int {
+	n, err := strconv.Atoi(c.Arg(i))
+	if err != nil {
+		return def
+	}
+	return n
+}
+
+// Rest joins every argument from i onwards
+func (c *Context) Rest(i int) string {
+	if i >= len(c.Args) {
+		return ""
+	}
+	return strings.Join(c.Args[i:], " ")
+}
+
+type Bot struct {
+	Server  *server.Server
+	Players *server.PlayerCache
+	Prefix  string
+	OnError func(cmd string, err error)
+
+	mu        sync.Mutex
+	commands  map[string]*Command
+	cooldowns map[string]time.Time
+	swept     time.Time
+}
+
+// Constructor to create Bot from IW4MWrapper instance
+func NewBot(w *wrapper.IW4MWrapper, prefix string) *Bot {
+	if prefix == "" {
+		prefix = DefaultPrefix
+	}
+	srv := server.NewServer(w)
+	b := &Bot{
+		Server:    srv,
+		Players:   server.NewPlayerCache(srv, 0),
+		Prefix:    prefix,
+		commands:  make(map[string]*Command),
+		cooldowns: make(map[string]time.Time),
+	}
+	b.Register(Command{
+		Name:        "help",
+		Usage:       "[command]",
+		Description: "lists commands or shows usage",
+		Handler:     b.help,
+	})
+	return b
+}
+
+// Register adds cmd under its name and aliases
+func (b *Bot) Register(cmd Command) error {
+	if cmd.Name == "" || cmd.Handler == nil {
+		return fmt.Errorf("command needs a name and a handler")
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c := &cmd
+	for _, name := range append([]string{cmd.Name}, cmd.Aliases...) {
+		name = strings.ToLower(name)
+		if _, exists := b.commands[name]; exists {
+			return fmt.Errorf("command %s already registered", name)
+		}
+		b.commands[name] = c
+	}
+	return nil
+}
+
+// Attach handles chat events published on bus
+func (b *Bot) Attach(bus *events.Bus) *events.Subscription {
+	return events.Handle(bus, b.Handle)
+}
+
+func (b *Bot) Say(message string) {
+	b.Server.SendCommand(commands.Say(message))
+}
+
+// Tell sends a private message to p. Names aren't a safe !tell target,
+// since they may contain spaces or match someone else, so p needs a
+// client ID
+func (b *Bot) Tell(p models.Player, message string) error {
+	if p.XUID == "" {
+		return fmt.Errorf("can't tell %s: no client id", p.Name)
+	}
+	b.Server.SendCommand(commands.Tell(commands.Target(p.Name, p.XUID), message))
+	return nil
+}
+
+// Handle dispatches a chat message if it starts with the bot prefix
+func (b *Bot) Handle(e events.ChatEvent) {
+	msg := strings.TrimSpace(utils.StripColorCodes(e.Message))
+	if !strings.HasPrefix(msg, b.Prefix) {
+		return
+	}
+
+	args := SplitArgs(strings.TrimPrefix(msg, b.Prefix))
+	if len(args) == 0 {
+		return
+	}
+	name := strings.ToLower(args[0])
+
+	b.mu.Lock()
+	cmd, ok := b.commands[name]
+	b.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	caller := b.lookup(e.Origin)
+	level := models.PlayerRole(caller.Role)
+
+	ctx := &Context{Bot: b, Event: e, Command: cmd, Caller: caller, Level: level, Args: args[1:]}
+
+	if level < cmd.MinLevel {
+		b.fail(cmd.Name, ctx.Reply("You are not allowed to use %s%s", b.Prefix, cmd.Name))
+		return
+	}
+	if len(ctx.Args) < cmd.MinArgs {
+		b.fail(cmd.Name, ctx.Reply("Usage: %s%s %s", b.Prefix, cmd.Name, cmd.Usage))
+		return
+	}
+	if wait := b.cooldown(cmd, caller); wait > 0 {
+		b.fail(cmd.Name, ctx.Reply("%s%s is on cooldown for %ds", b.Prefix, cmd.Name, int(wait.Seconds()+0.5)))
+		return
+	}
+
+	if err := cmd.Handler(ctx); err != nil {
+		b.fail(cmd.Name, err)
+		b.fail(cmd.Name, ctx.Reply("%s%s failed: %s", b.Prefix, cmd.Name, err))
+	}
+}
+
+func (b *Bot) fail(cmd string, err error) {
+	if err != nil && b.OnError != nil {
+		b.OnError(cmd, err)
+	}
+}
+
+// cooldown returns how long caller still has to wait, starting a new
+// cooldown period when there is nothing left
+func (b *Bot) cooldown(cmd *Command, caller models.Player) time.Duration {
+	if cmd.Cooldown <= 0 {
+		return 0
+	}
+
+	key := cmd.Name + "\x00" + caller.XUID + "\x00" + caller.Name
+	now := time.Now()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if until, ok := b.cooldowns[key]; ok && now.Before(until) {
+		return until.Sub(now)
+	}
+	b.cooldowns[key] = now.Add(cmd.Cooldown)
+
+	// drop finished cooldowns now and then so callers who left don't pile up
+	if now.Sub(b.swept) >= time.Minute {
+		b.swept = now
+		for k, until := range b.cooldowns {
+			if !now.Before(until) {
+				delete(b.cooldowns, k)
+			}
+		}
+	}
+	return 0
+}
+
+// lookup finds the online player who sent a message
+func (b *Bot) lookup(origin string) models.Player {
+	if p, ok := b.Players.Find(origin); ok {
+		return p
+	}
+	return models.Player{Name: strings.TrimSpace(utils.StripColorCodes(origin)), Role: "user"}
+}
+
+func (b *Bot) help(ctx *Context) error {
+	if name := strings.ToLower(strings.TrimPrefix(ctx.Arg(0), b.Prefix)); name != "" {
+		b.mu.Lock()
+		cmd, ok := b.commands[name]
+		b.mu.Unlock()
+		if !ok {
+			return ctx.Reply("Unknown command %s", name)
+		}
+		return ctx.Reply("%s%s %s - %s", b.Prefix, cmd.Name, cmd.Usage, cmd.Description)
+	}
+
+	b.mu.Lock()
+	seen := make(map[*Command]bool)
+	var names []string
+	for _, cmd := range b.commands {
+		if seen[cmd] || cmd.MinLevel > ctx.Level {
+			continue
+		}
+		seen[cmd] = true
+		names = append(names, b.Prefix+cmd.Name)
+	}
+	b.mu.Unlock()
+
+	sort.Strings(names)
+	return ctx.Reply("Commands: %s", strings.Join(names, ", "))
+}
+
+// SplitArgs splits s on whitespace, keeping "quoted arguments" together
+func SplitArgs(s string) []string {
+	var (
+		args    []string
+		current strings.Builder
+		quoted  bool
+		started bool
+	)
+
+	for _, r := range s {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			started = true
+		case !quoted && (r == ' ' || r == '\t'):
+			if started {
+				args = append(args, current.String())
+				current.Reset()
+				started = false
+			}
+		default:
+			current.WriteRune(r)
+			started = true
+		}
+	}
+	if started {
+		args = append(args, current.String())
+	}
+	return args
+}
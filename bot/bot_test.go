@@ -0,0 +1,41 @@
+package bot
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Yallamaztar/go-iw4m/models"
+	"github.com/Yallamaztar/go-iw4m/wrapper"
+)
+
+func TestTellNeedsClientID(t *testing.T) {
+	b := NewBot(&wrapper.IW4MWrapper{}, "")
+	if err := b.Tell(models.Player{Name: "Some Guy"}, "hi"); err == nil {
+		t.Error("Tell without a client id succeeded, want error")
+	}
+}
+
+func TestCooldownPrunes(t *testing.T) {
+	b := NewBot(&wrapper.IW4MWrapper{}, "")
+	cmd := &Command{Name: "rules", Cooldown: time.Minute}
+
+	if wait := b.cooldown(cmd, models.Player{Name: "a", XUID: "1"}); wait != 0 {
+		t.Fatalf("first use waits %v", wait)
+	}
+	if wait := b.cooldown(cmd, models.Player{Name: "a", XUID: "1"}); wait <= 0 {
+		t.Fatal("second use within the cooldown didn't wait")
+	}
+
+	// let the cooldown of "a" run out, then another caller triggers a sweep
+	b.mu.Lock()
+	for k := range b.cooldowns {
+		b.cooldowns[k] = time.Now().Add(-time.Second)
+	}
+	b.swept = time.Time{}
+	b.mu.Unlock()
+
+	b.cooldown(cmd, models.Player{Name: "b", XUID: "2"})
+	if n := len(b.cooldowns); n != 1 {
+		t.Errorf("%d cooldowns kept; want only the running one", n)
+	}
+}
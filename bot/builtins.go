@@ -0,0 +1,131 @@
+package bot
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Yallamaztar/go-iw4m/player"
+)
+
+// RulesCommand replies with the server rules from the About page
+func RulesCommand() Command {
+	return Command{
+		Name:        "rules",
+		Description: "shows the server rules",
+		Cooldown:    30 * time.Second,
+		Handler: func(ctx *Context) error {
+			rules := ctx.Bot.Server.Rules()
+			if len(rules) == 0 {
+				return fmt.Errorf("no rules found")
+			}
+			for i, rule := range rules {
+				if err := ctx.Reply("%d. %s", i+1, rule); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+}
+
+// TopCommand broadcasts the top N players (default 3)
+func TopCommand() Command {
+	return Command{
+		Name:        "top",
+		Usage:       "[count]",
+		Description: "shows the top players",
+		Cooldown:    time.Minute,
+		Handler: func(ctx *Context) error {
+			count := ctx.IntArg(0, 3)
+			if count < 1 || count > 10 {
+				count = 3
+			}
+
+			top, err := ctx.Bot.Server.TopPlayers(count)
+			if err != nil {
+				return err
+			}
+			for _, p := range top {
+				ctx.Broadcast("%s %s - %s", p.Rank, p.Name, p.Rating)
+			}
+			return nil
+		},
+	}
+}
+
+// StatsCommand replies with the caller's stats from the advanced stats page
+func StatsCommand() Command {
+	return Command{
+		Name:        "stats",
+		Description: "shows your stats",
+		Cooldown:    15 * time.Second,
+		Handler: func(ctx *Context) error {
+			if ctx.Caller.XUID == "" {
+				return fmt.Errorf("could not find you in the player list")
+			}
+
+			stats, err := player.NewPlayer(ctx.Bot.Server.Wrapper).AdvancedStats(ctx.Caller.XUID)
+			if err != nil {
+				return err
+			}
+
+			var parts []string
+			for i, entry := range stats.PlayerStats {
+				if i == 5 {
+					break
+				}
+				parts = append(parts, fmt.Sprintf("%s: %s", entry.Key, entry.Value))
+			}
+			return ctx.Reply("%s", strings.Join(parts, " | "))
+		},
+	}
+}
+
+// CompareCommand compares the caller, or a given player, with another player
+func CompareCommand() Command {
+	return Command{
+		Name:        "compare",
+		Usage:       "<player> [player]",
+		Description: "compares two players",
+		MinArgs:     1,
+		Cooldown:    30 * time.Second,
+		Handler: func(ctx *Context) error {
+			p := player.NewPlayer(ctx.Bot.Server.Wrapper)
+
+			resolve := func(name string) (string, error) {
+				id, err := p.Resolver().ResolveName(name)
+				if err != nil {
+					return "", err
+				}
+				return id.ClientID, nil
+			}
+
+			a := ctx.Caller.XUID
+			b, err := resolve(ctx.Arg(0))
+			if err != nil {
+				return err
+			}
+			if len(ctx.Args) > 1 {
+				a = b
+				if b, err = resolve(ctx.Arg(1)); err != nil {
+					return err
+				}
+			}
+			if a == "" {
+				return fmt.Errorf("could not find you in the player list")
+			}
+
+			c, err := p.Compare(a, b)
+			if err != nil {
+				return err
+			}
+			for _, line := range c.ChatLines() {
+				if err := ctx.Reply("%s", line); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,226 @@
+package commands
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Yallamaztar/go-iw4m/models"
+	"github.com/Yallamaztar/go-iw4m/stats"
+)
+
+// Actions shared by the moderation packages (automod, spam, namepolicy,
+// connpolicy): what to do about a player, how often they offended and who
+// is exempt
+
+type ActionKind string
+
+const (
+	ActionLog      ActionKind = "log"
+	ActionTell     ActionKind = "tell"
+	ActionWarn     ActionKind = "warn"
+	ActionMute     ActionKind = "mute"
+	ActionTempMute ActionKind = "tempmute"
+	ActionFlag     ActionKind = "flag"
+	ActionKick     ActionKind = "kick"
+	ActionTempBan  ActionKind = "tempban"
+	ActionBan      ActionKind = "ban"
+)
+
+type Action struct {
+	Kind     ActionKind
+	Duration time.Duration
+}
+
+func (a Action) timed() bool {
+	return a.Kind == ActionTempBan || a.Kind == ActionTempMute
+}
+
+func (a Action) String() string {
+	if a.timed() {
+		return fmt.Sprintf("%s:%s", a.Kind, formatDuration(a.Duration))
+	}
+	return string(a.Kind)
+}
+
+// ParseAction parses "warn", "kick" and the other kinds, or
+// "tempban:<N minutes|duration>" and "tempmute:<...>"
+func ParseAction(s string) (Action, error) {
+	kind, arg, _ := strings.Cut(strings.ToLower(strings.TrimSpace(s)), ":")
+	a := Action{Kind: ActionKind(strings.TrimSpace(kind))}
+
+	switch a.Kind {
+	case ActionLog, ActionTell, ActionWarn, ActionMute, ActionFlag, ActionKick, ActionBan:
+		return a, nil
+	case ActionTempBan, ActionTempMute:
+		arg = strings.TrimSpace(arg)
+		if arg == "" {
+			return a, fmt.Errorf("%s needs a duration, e.g. %s:30", a.Kind, a.Kind)
+		}
+		if n, err := strconv.Atoi(arg); err == nil {
+			a.Duration = time.Duration(n) * time.Minute
+		} else if d, err := stats.ParseDuration(arg); err == nil {
+			a.Duration = d
+		} else {
+			return a, fmt.Errorf("invalid %s duration %q", a.Kind, arg)
+		}
+		return a, nil
+	}
+	return a, fmt.Errorf("unknown action %q", s)
+}
+
+func (a Action) MarshalText() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+// UnmarshalText leaves a unset for empty text so configs can apply their
+// own default
+func (a *Action) UnmarshalText(text []byte) error {
+	if strings.TrimSpace(string(text)) == "" {
+		*a = Action{}
+		return nil
+	}
+	parsed, err := ParseAction(string(text))
+	if err != nil {
+		return err
+	}
+	*a = parsed
+	return nil
+}
+
+// Command returns the console command applying a to target, or "" for
+// ActionLog
+func (a Action) Command(target, reason string) string {
+	switch a.Kind {
+	case ActionTell:
+		return Tell(target, reason)
+	case ActionWarn:
+		return Warn(target, reason)
+	case ActionMute:
+		return Mute(target, reason)
+	case ActionTempMute:
+		return TempMute(target, a.Duration, reason)
+	case ActionFlag:
+		return Flag(target, reason)
+	case ActionKick:
+		return Kick(target, reason)
+	case ActionTempBan:
+		return TempBan(target, a.Duration, reason)
+	case ActionBan:
+		return Ban(target, reason)
+	}
+	return ""
+}
+
+// Escalate picks the action for the Nth offense, repeating the last one
+// once the list is exhausted
+func Escalate(actions []Action, offense int) Action {
+	if len(actions) == 0 {
+		return Action{Kind: ActionLog}
+	}
+	offense = min(max(offense, 1), len(actions))
+	return actions[offense-1]
+}
+
+const DefaultResetAfter = 24 * time.Hour
+
+type rung struct {
+	count int
+	last  time.Time
+}
+
+// Ladder counts offenses per player. A player's count starts over after
+// ResetAfter without an offense
+type Ladder struct {
+	ResetAfter time.Duration
+
+	mu    sync.Mutex
+	rungs map[string]*rung
+}
+
+// Constructor to create Ladder; a resetAfter of 0 uses DefaultResetAfter
+func NewLadder(resetAfter time.Duration) *Ladder {
+	if resetAfter <= 0 {
+		resetAfter = DefaultResetAfter
+	}
+	return &Ladder{ResetAfter: resetAfter, rungs: make(map[string]*rung)}
+}
+
+// Next records an offense for key and returns how many it now has
+func (l *Ladder) Next(key string) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	r, ok := l.rungs[key]
+	if !ok || time.Since(r.last) > l.ResetAfter {
+		r = &rung{}
+		l.rungs[key] = r
+	}
+	r.count++
+	r.last = time.Now()
+	return r.count
+}
+
+const DefaultExemptRole = models.RoleTrusted
+
+// Exemption is the lowest role a moderation config leaves alone, decoded
+// from a role name such as "moderator" or "none" to check everyone. The
+// zero value is unset and exempts DefaultExemptRole and up
+type Exemption struct {
+	role models.Role
+	set  bool
+	none bool
+}
+
+// ExemptFrom exempts role and every role above it
+func ExemptFrom(role models.Role) Exemption {
+	return Exemption{role: role, set: true}
+}
+
+// ExemptNobody checks every player, staff included
+var ExemptNobody = Exemption{set: true, none: true}
+
+// Exempt reports whether a player with role is left alone
+func (e Exemption) Exempt(role models.Role) bool {
+	switch {
+	case !e.set:
+		return role >= DefaultExemptRole
+	case e.none:
+		return false
+	}
+	return role >= e.role
+}
+
+func (e Exemption) String() string {
+	switch {
+	case !e.set:
+		return ""
+	case e.none:
+		return "none"
+	}
+	return e.role.String()
+}
+
+func (e Exemption) MarshalText() ([]byte, error) {
+	return []byte(e.String()), nil
+}
+
+func (e *Exemption) UnmarshalText(text []byte) error {
+	s := strings.TrimSpace(string(text))
+	switch strings.ToLower(s) {
+	case "":
+		*e = Exemption{}
+		return nil
+	case "none", "nobody":
+		*e = ExemptNobody
+		return nil
+	}
+	role, err := models.ParseRole(s)
+	if err != nil {
+		return err
+	}
+	*e = ExemptFrom(role)
+	return nil
+}
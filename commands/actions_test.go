@@ -0,0 +1,151 @@
+package commands
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/Yallamaztar/go-iw4m/models"
+)
+
+func TestParseAction(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Action
+	}{
+		{"warn", Action{Kind: ActionWarn}},
+		{" Kick ", Action{Kind: ActionKick}},
+		{"flag", Action{Kind: ActionFlag}},
+		{"tempban:30", Action{Kind: ActionTempBan, Duration: 30 * time.Minute}},
+		{"tempban:2h", Action{Kind: ActionTempBan, Duration: 2 * time.Hour}},
+		{"tempmute:1d", Action{Kind: ActionTempMute, Duration: 24 * time.Hour}},
+	}
+	for _, tt := range tests {
+		got, err := ParseAction(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseAction(%q) = %v, %v; want %v", tt.in, got, err, tt.want)
+		}
+	}
+	for _, in := range []string{"", "tempban", "tempban:soon", "explode"} {
+		if _, err := ParseAction(in); err == nil {
+			t.Errorf("ParseAction(%q) succeeded, want error", in)
+		}
+	}
+}
+
+func TestActionJSON(t *testing.T) {
+	var actions []Action
+	if err := json.Unmarshal([]byte(`["tell", "tempban:90"]`), &actions); err != nil {
+		t.Fatal(err)
+	}
+	want := []Action{{Kind: ActionTell}, {Kind: ActionTempBan, Duration: 90 * time.Minute}}
+	if len(actions) != 2 || actions[0] != want[0] || actions[1] != want[1] {
+		t.Fatalf("Unmarshal = %v; want %v", actions, want)
+	}
+
+	data, err := json.Marshal(actions)
+	if err != nil || string(data) != `["tell","tempban:90m"]` {
+		t.Errorf("Marshal = %s, %v", data, err)
+	}
+
+	if err := json.Unmarshal([]byte(`["explode"]`), &actions); err == nil {
+		t.Error("Unmarshal of an unknown action succeeded, want error")
+	}
+}
+
+func TestActionCommand(t *testing.T) {
+	tests := []struct {
+		action Action
+		want   string
+	}{
+		{Action{Kind: ActionLog}, ""},
+		{Action{Kind: ActionTell}, "!tell @7 stop that"},
+		{Action{Kind: ActionKick}, "!kick @7 stop that"},
+		{Action{Kind: ActionTempBan, Duration: 2 * time.Hour}, "!tempban @7 2h stop that"},
+		{Action{Kind: ActionTempMute, Duration: 45 * time.Minute}, "!tempmute @7 45m stop that"},
+	}
+	for _, tt := range tests {
+		if got := tt.action.Command("@7", "stop   that"); got != tt.want {
+			t.Errorf("%v.Command = %q; want %q", tt.action, got, tt.want)
+		}
+	}
+}
+
+func TestEscalate(t *testing.T) {
+	ladder := []Action{{Kind: ActionTell}, {Kind: ActionKick}}
+	tests := []struct {
+		offense int
+		want    ActionKind
+	}{
+		{0, ActionTell},
+		{1, ActionTell},
+		{2, ActionKick},
+		{5, ActionKick},
+	}
+	for _, tt := range tests {
+		if got := Escalate(ladder, tt.offense); got.Kind != tt.want {
+			t.Errorf("Escalate(%d) = %v; want %v", tt.offense, got, tt.want)
+		}
+	}
+	if got := Escalate(nil, 1); got.Kind != ActionLog {
+		t.Errorf("Escalate(nil) = %v; want log", got)
+	}
+}
+
+func TestLadder(t *testing.T) {
+	l := NewLadder(time.Hour)
+	for want := 1; want <= 3; want++ {
+		if got := l.Next("a"); got != want {
+			t.Errorf("Next(a) = %d; want %d", got, want)
+		}
+	}
+	if got := l.Next("b"); got != 1 {
+		t.Errorf("Next(b) = %d; want 1", got)
+	}
+
+	l.rungs["a"].last = time.Now().Add(-2 * time.Hour)
+	if got := l.Next("a"); got != 1 {
+		t.Errorf("Next(a) after reset = %d; want 1", got)
+	}
+}
+
+func TestExemption(t *testing.T) {
+	tests := []struct {
+		config string
+		role   models.Role
+		want   bool
+	}{
+		{"", models.RoleUser, false},
+		{"", models.RoleTrusted, true},
+		{"moderator", models.RoleTrusted, false},
+		{"moderator", models.RoleOwner, true},
+		{"user", models.RoleUser, true},
+		{"user", models.RoleBanned, false},
+		{"none", models.RoleOwner, false},
+		{"none", models.RoleConsole, false},
+	}
+	for _, tt := range tests {
+		var e Exemption
+		if err := json.Unmarshal([]byte(`"`+tt.config+`"`), &e); err != nil {
+			t.Fatalf("decoding %q: %v", tt.config, err)
+		}
+		if got := e.Exempt(tt.role); got != tt.want {
+			t.Errorf("Exemption(%q).Exempt(%v) = %v; want %v", tt.config, tt.role, got, tt.want)
+		}
+	}
+
+	var e Exemption
+	if err := json.Unmarshal([]byte(`"wizard"`), &e); err == nil {
+		t.Error("decoding an unknown role succeeded, want error")
+	}
+	for _, e := range []Exemption{{}, ExemptNobody, ExemptFrom(models.RoleUser)} {
+		data, err := json.Marshal(e)
+		var back Exemption
+		if err == nil {
+			err = json.Unmarshal(data, &back)
+		}
+		if err != nil || back != e {
+			t.Errorf("round trip of %q = %q, %v", e, back, err)
+		}
+	}
+}
@@ -1 +1,73 @@
 package commands
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Helpers building IW4MAdmin console commands for Server.SendCommand
+
+// Target formats a client ID as an IW4MAdmin @clientId target, falling back
+// to the player's name when no ID is known
+func Target(name, clientID string) string {
+	if clientID != "" {
+		return "@" + clientID
+	}
+	return name
+}
+
+func Say(message string) string {
+	return fmt.Sprintf("!say %s", clean(message))
+}
+
+func Tell(target, message string) string {
+	return fmt.Sprintf("!tell %s %s", target, clean(message))
+}
+
+func clean(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func Warn(target, reason string) string {
+	return fmt.Sprintf("!warn %s %s", target, clean(reason))
+}
+
+func Kick(target, reason string) string {
+	return fmt.Sprintf("!kick %s %s", target, clean(reason))
+}
+
+// TempBan formats the duration the way IW4MAdmin expects it (30m, 2h, 1d)
+func TempBan(target string, duration time.Duration, reason string) string {
+	return fmt.Sprintf("!tempban %s %s %s", target, formatDuration(duration), clean(reason))
+}
+
+func Ban(target, reason string) string {
+	return fmt.Sprintf("!ban %s %s", target, clean(reason))
+}
+
+func formatDuration(d time.Duration) string {
+	switch {
+	case d >= 24*time.Hour && d%(24*time.Hour) == 0:
+		return fmt.Sprintf("%dd", d/(24*time.Hour))
+	case d >= time.Hour && d%time.Hour == 0:
+		return fmt.Sprintf("%dh", d/time.Hour)
+	}
+	minutes := int(d.Minutes())
+	if minutes < 1 {
+		minutes = 1
+	}
+	return fmt.Sprintf("%dm", minutes)
+}
+
+func Mute(target, reason string) string {
+	return fmt.Sprintf("!mute %s %s", target, clean(reason))
+}
+
+func TempMute(target string, duration time.Duration, reason string) string {
+	return fmt.Sprintf("!tempmute %s %s %s", target, formatDuration(duration), clean(reason))
+}
+
+func Flag(target, reason string) string {
+	return fmt.Sprintf("!flag %s %s", target, clean(reason))
+}
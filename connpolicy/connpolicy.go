@@ -0,0 +1,265 @@
+package connpolicy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/netip"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Yallamaztar/go-iw4m/commands"
+	"github.com/Yallamaztar/go-iw4m/models"
+	"github.com/Yallamaztar/go-iw4m/player"
+	"github.com/Yallamaztar/go-iw4m/server"
+	"github.com/Yallamaztar/go-iw4m/utils"
+	"github.com/Yallamaztar/go-iw4m/wrapper"
+)
+
+const DefaultInterval = 15 * time.Second
+
+// Rules is the JSON policy file. Deny lists win over allow lists; a
+// non-empty allow_countries list rejects every other country, except for
+// addresses in allow_cidrs
+type Rules struct {
+	AllowCIDRs     []string `json:"allow_cidrs"`
+	DenyCIDRs      []string `json:"deny_cidrs"`
+	AllowCountries []string `json:"allow_countries"`
+	DenyCountries  []string `json:"deny_countries"`
+	// ExemptRole and up are never kicked or flagged
+	ExemptRole commands.Exemption `json:"exempt_role"`
+	// Action is log, flag or kick
+	Action commands.Action `json:"action"`
+	Reason string          `json:"reason"`
+
+	allow []netip.Prefix
+	deny  []netip.Prefix
+}
+
+// LoadRules reads and validates a policy file
+func LoadRules(path string) (*Rules, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var r Rules
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := r.Compile(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &r, nil
+}
+
+func parsePrefixes(list []string) ([]netip.Prefix, error) {
+	var prefixes []netip.Prefix
+	for _, s := range list {
+		s = strings.TrimSpace(s)
+		if !strings.Contains(s, "/") {
+			addr, err := netip.ParseAddr(s)
+			if err != nil {
+				return nil, err
+			}
+			prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+		p, err := netip.ParsePrefix(s)
+		if err != nil {
+			return nil, err
+		}
+		prefixes = append(prefixes, p.Masked())
+	}
+	return prefixes, nil
+}
+
+// Compile parses the CIDR lists and fills in defaults
+func (r *Rules) Compile() error {
+	var err error
+	if r.allow, err = parsePrefixes(r.AllowCIDRs); err != nil {
+		return err
+	}
+	if r.deny, err = parsePrefixes(r.DenyCIDRs); err != nil {
+		return err
+	}
+
+	switch r.Action.Kind {
+	case "":
+		r.Action.Kind = commands.ActionLog
+	case commands.ActionLog, commands.ActionFlag, commands.ActionKick:
+	default:
+		return fmt.Errorf("action must be log, flag or kick, not %s", r.Action)
+	}
+	if r.Reason == "" {
+		r.Reason = "Connection not allowed from your region"
+	}
+	return nil
+}
+
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(strings.TrimSpace(v), strings.TrimSpace(s)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Check returns why a client violates the policy, or "" when it doesn't
+func (r *Rules) Check(ip, country string) string {
+	allowed := false
+	if addr, err := netip.ParseAddr(strings.TrimSpace(ip)); err == nil {
+		addr = addr.Unmap()
+		for _, p := range r.deny {
+			if p.Contains(addr) {
+				return fmt.Sprintf("ip %s is in denied range %s", addr, p)
+			}
+		}
+		for _, p := range r.allow {
+			if p.Contains(addr) {
+				allowed = true
+				break
+			}
+		}
+	}
+
+	if country == "" {
+		return ""
+	}
+	if containsFold(r.DenyCountries, country) {
+		return fmt.Sprintf("country %s is denied", country)
+	}
+	if !allowed && len(r.AllowCountries) > 0 && !containsFold(r.AllowCountries, country) {
+		return fmt.Sprintf("country %s is not allowed", country)
+	}
+	return ""
+}
+
+type Decision struct {
+	Time     time.Time           `json:"time"`
+	Client   models.RecentClient `json:"client"`
+	ClientID string              `json:"client_id"`
+	Reason   string              `json:"reason"`
+	Action   commands.Action     `json:"action"`
+	Command  string              `json:"command"`
+}
+
+type Enforcer struct {
+	Server   *server.Server
+	Player   *player.Player
+	Rules    *Rules
+	Interval time.Duration
+	// OnDecision is called for every violation, after the action was taken
+	OnDecision func(Decision)
+	// OnError receives poll errors and violations left alone because the
+	// client's role couldn't be checked
+	OnError func(error)
+
+	seen map[string]bool
+}
+
+// Constructor to create Enforcer from IW4MWrapper instance
+func NewEnforcer(w *wrapper.IW4MWrapper, rules *Rules) *Enforcer {
+	return &Enforcer{
+		Server:   server.NewServer(w),
+		Player:   player.NewPlayer(w),
+		Rules:    rules,
+		Interval: DefaultInterval,
+	}
+}
+
+func (e *Enforcer) errorf(format string, args ...any) {
+	if e.OnError != nil {
+		e.OnError(fmt.Errorf(format, args...))
+	}
+}
+
+// Evaluate applies the policy to a single client
+func (e *Enforcer) Evaluate(c models.RecentClient) *Decision {
+	reason := e.Rules.Check(c.IPAddress, c.Country)
+	if reason == "" {
+		return nil
+	}
+
+	// never act on someone whose role couldn't be checked; they may be staff
+	clientID := utils.ClientIDFromLink(c.Link)
+	if clientID == "" {
+		e.errorf("skipping %s (%s, %s): no client id to check the role of: %s", c.Name, c.IPAddress, c.Country, reason)
+		return nil
+	}
+	info, err := e.Player.ClientInfo(clientID)
+	if err != nil {
+		e.errorf("skipping %s (#%s): role lookup failed: %w: %s", c.Name, clientID, err, reason)
+		return nil
+	}
+	if e.Rules.ExemptRole.Exempt(info.Role) {
+		return nil
+	}
+
+	d := &Decision{
+		Time:     time.Now(),
+		Client:   c,
+		ClientID: clientID,
+		Reason:   reason,
+		Action:   e.Rules.Action,
+	}
+	message := e.Rules.Reason
+	if d.Action.Kind == commands.ActionFlag {
+		// flags are read by staff, who want the detail
+		message += " (" + reason + ")"
+	}
+	d.Command = d.Action.Command(commands.Target(c.Name, clientID), message)
+	if d.Command != "" {
+		e.Server.SendCommand(d.Command)
+	}
+
+	if e.OnDecision != nil {
+		e.OnDecision(*d)
+	}
+	return d
+}
+
+// Poll checks clients that appeared in RecentClients since the last poll.
+// The first poll only records who is there
+func (e *Enforcer) Poll() error {
+	clients, err := e.Server.RecentClients(0)
+	if err != nil {
+		return err
+	}
+
+	first := e.seen == nil
+	next := make(map[string]bool, len(clients))
+	for _, c := range clients {
+		k := c.Link + "\x00" + c.IPAddress
+		next[k] = true
+		if first || e.seen[k] {
+			continue
+		}
+		e.Evaluate(c)
+	}
+	e.seen = next
+	return nil
+}
+
+// Run polls every Interval until ctx is cancelled
+func (e *Enforcer) Run(ctx context.Context) error {
+	interval := e.Interval
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := e.Poll(); err != nil && e.OnError != nil {
+			e.OnError(err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
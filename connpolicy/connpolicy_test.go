@@ -0,0 +1,81 @@
+package connpolicy
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Yallamaztar/go-iw4m/commands"
+	"github.com/Yallamaztar/go-iw4m/models"
+)
+
+func TestRulesCheck(t *testing.T) {
+	r := &Rules{
+		AllowCIDRs:     []string{"10.0.0.0/8", "203.0.113.7"},
+		DenyCIDRs:      []string{"198.51.100.0/24", "10.6.6.0/24"},
+		AllowCountries: []string{"Germany", "Netherlands"},
+		DenyCountries:  []string{"Atlantis"},
+	}
+	if err := r.Compile(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		ip, country string
+		denied      bool
+	}{
+		{"198.51.100.20", "Germany", true},
+		{"10.6.6.1", "Germany", true},
+		{"10.1.2.3", "France", false},
+		{"203.0.113.7", "France", false},
+		// an allowed range skips allow_countries, not deny_countries
+		{"10.1.2.3", "Atlantis", true},
+		{"203.0.113.7", "Atlantis", true},
+		{"::ffff:198.51.100.1", "Germany", true},
+		{"192.0.2.1", "germany", false},
+		{"192.0.2.1", "France", true},
+		{"192.0.2.1", "Atlantis", true},
+		{"192.0.2.1", "", false},
+		{"not-an-ip", "Netherlands", false},
+	}
+	for _, tt := range tests {
+		if got := r.Check(tt.ip, tt.country); (got != "") != tt.denied {
+			t.Errorf("Check(%q, %q) = %q; want denied=%v", tt.ip, tt.country, got, tt.denied)
+		}
+	}
+}
+
+func TestLoadRules(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.json")
+	data := `{"deny_cidrs": ["192.0.2.0/24"], "exempt_role": "moderator", "action": "kick", "reason": ""}`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := LoadRules(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.ExemptRole != commands.ExemptFrom(models.RoleModerator) || r.Action.Kind != commands.ActionKick || r.Reason == "" {
+		t.Errorf("LoadRules = %+v", r)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"action": ""}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if r, err := LoadRules(path); err != nil || r.Action.Kind != commands.ActionLog {
+		t.Errorf("LoadRules with an empty action = %+v, %v; want log", r, err)
+	}
+}
+
+func TestRulesCompile(t *testing.T) {
+	for _, r := range []*Rules{
+		{DenyCIDRs: []string{"300.0.0.0/8"}},
+		{AllowCIDRs: []string{"nope"}},
+		{Action: commands.Action{Kind: commands.ActionBan}},
+	} {
+		if err := r.Compile(); err == nil {
+			t.Errorf("Compile(%+v) succeeded, want error", r)
+		}
+	}
+}
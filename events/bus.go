@@ -0,0 +1,232 @@
+package events
+
+import (
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// Policy decides what happens when a subscriber's buffer is full
+type Policy int
+
+const (
+	// Block waits until the subscriber has room
+	Block Policy = iota
+	// DropNewest discards the event being published
+	DropNewest
+	// DropOldest discards the oldest buffered event to make room
+	DropOldest
+)
+
+const DefaultBuffer = 64
+
+type Handler func(Event)
+
+type SubscribeOptions struct {
+	Types  []Type
+	Buffer int
+	Policy Policy
+}
+
+type Subscription struct {
+	bus     *Bus
+	ch      chan Event
+	types   map[Type]bool
+	policy  Policy
+	handler Handler
+	mu      sync.Mutex
+	done    chan struct{}
+	once    sync.Once
+	exited  chan struct{}
+	dropped atomic.Uint64
+}
+
+type Bus struct {
+	mu     sync.RWMutex
+	subs   map[*Subscription]struct{}
+	closed bool
+	done   chan struct{}
+	once   sync.Once
+}
+
+// Constructor to create an empty Bus
+func NewBus() *Bus {
+	return &Bus{subs: make(map[*Subscription]struct{}), done: make(chan struct{})}
+}
+
+// Subscribe registers handler for the event types in opts (all types when empty).
+// Each subscription has its own buffer and goroutine. With the default Block
+// policy a full buffer makes Publish wait, which stalls the publisher and so
+// every other subscriber until the handler catches up; handlers that may be
+// slow should use DropNewest or DropOldest
+func (b *Bus) Subscribe(handler Handler, opts SubscribeOptions) *Subscription {
+	if opts.Buffer <= 0 {
+		opts.Buffer = DefaultBuffer
+	}
+
+	s := &Subscription{
+		bus:     b,
+		ch:      make(chan Event, opts.Buffer),
+		policy:  opts.Policy,
+		handler: handler,
+		done:    make(chan struct{}),
+		exited:  make(chan struct{}),
+	}
+	if len(opts.Types) > 0 {
+		s.types = make(map[Type]bool, len(opts.Types))
+		for _, t := range opts.Types {
+			s.types[t] = true
+		}
+	}
+
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		s.once.Do(func() { close(s.done) })
+		close(s.exited)
+		return s
+	}
+	b.subs[s] = struct{}{}
+	b.mu.Unlock()
+
+	go s.run()
+	return s
+}
+
+// On registers handler for a single event type with default options
+func (b *Bus) On(t Type, handler Handler) *Subscription {
+	return b.Subscribe(handler, SubscribeOptions{Types: []Type{t}})
+}
+
+// Handle registers a handler typed on the concrete event struct, e.g.
+// Handle(bus, func(e ChatEvent) { ... }). Events are published as values,
+// so T must not be a pointer
+func Handle[T Event](b *Bus, fn func(T), opts ...SubscribeOptions) *Subscription {
+	if reflect.TypeFor[T]().Kind() == reflect.Pointer {
+		panic("events: Handle needs an event value type such as ChatEvent, not " + reflect.TypeFor[T]().String())
+	}
+	var zero T
+	o := SubscribeOptions{}
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+	o.Types = []Type{zero.Type()}
+
+	return b.Subscribe(func(e Event) {
+		if v, ok := e.(T); ok {
+			fn(v)
+		}
+	}, o)
+}
+
+// Publish fans e out to every matching subscriber
+func (b *Bus) Publish(e Event) {
+	b.mu.RLock()
+	if b.closed {
+		b.mu.RUnlock()
+		return
+	}
+	targets := make([]*Subscription, 0, len(b.subs))
+	for s := range b.subs {
+		if s.types == nil || s.types[e.Type()] {
+			targets = append(targets, s)
+		}
+	}
+	b.mu.RUnlock()
+
+	for _, s := range targets {
+		s.deliver(e)
+	}
+}
+
+// Close stops every subscription; later publishes are ignored
+func (b *Bus) Close() {
+	// release blocked publishers before waiting for the lock
+	b.once.Do(func() { close(b.done) })
+
+	b.mu.Lock()
+	subs := b.subs
+	b.subs = make(map[*Subscription]struct{})
+	b.closed = true
+	b.mu.Unlock()
+
+	for s := range subs {
+		s.stop()
+	}
+}
+
+func (s *Subscription) deliver(e Event) {
+	switch s.policy {
+	case DropNewest:
+		select {
+		case s.ch <- e:
+		default:
+			s.dropped.Add(1)
+		}
+	case DropOldest:
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		for {
+			select {
+			case s.ch <- e:
+				return
+			default:
+			}
+			select {
+			case <-s.ch:
+				s.dropped.Add(1)
+			default:
+			}
+		}
+	default:
+		select {
+		case s.ch <- e:
+		case <-s.done:
+		case <-s.bus.done:
+		}
+	}
+}
+
+func (s *Subscription) run() {
+	defer close(s.exited)
+	for {
+		select {
+		case e := <-s.ch:
+			s.handler(e)
+		case <-s.done:
+			// hand over what was buffered before the stop
+			for {
+				select {
+				case e := <-s.ch:
+					s.handler(e)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (s *Subscription) stop() {
+	s.once.Do(func() { close(s.done) })
+}
+
+// Close removes the subscription from its bus. Events already buffered are
+// still handled; Wait blocks until they are
+func (s *Subscription) Close() {
+	s.stop()
+	s.bus.mu.Lock()
+	delete(s.bus.subs, s)
+	s.bus.mu.Unlock()
+}
+
+// Wait blocks until the subscription is closed and its handler has
+// returned for every buffered event. It must not be called from the handler
+func (s *Subscription) Wait() {
+	<-s.exited
+}
+
+// Dropped returns how many events were discarded by the backpressure policy
+func (s *Subscription) Dropped() uint64 {
+	return s.dropped.Load()
+}
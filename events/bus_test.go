@@ -0,0 +1,66 @@
+package events
+
+import (
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/Yallamaztar/go-iw4m/models"
+)
+
+func TestCloseHandlesBuffered(t *testing.T) {
+	bus := NewBus()
+	release := make(chan struct{})
+	var mu sync.Mutex
+	var got []string
+
+	sub := Handle(bus, func(e ChatEvent) {
+		<-release
+		mu.Lock()
+		got = append(got, e.Message)
+		mu.Unlock()
+	}, SubscribeOptions{Buffer: 8})
+
+	for _, m := range []string{"a", "b", "c"} {
+		bus.Publish(ChatEvent{Chat: models.Chat{Message: m}})
+	}
+	sub.Close()
+	close(release)
+	sub.Wait()
+
+	if strings.Join(got, "") != "abc" {
+		t.Errorf("handled %q after Close; want every buffered event", got)
+	}
+}
+
+func TestWaitAfterBusClose(t *testing.T) {
+	bus := NewBus()
+	bus.Close()
+	// subscribing to a closed bus must not leave Wait hanging
+	bus.On(TypeChat, func(Event) {}).Wait()
+}
+
+func TestHandlePointer(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Handle with a pointer type did not panic")
+		}
+	}()
+	Handle(NewBus(), func(*ChatEvent) {})
+}
+
+func TestEventJSON(t *testing.T) {
+	data, err := json.Marshal(ChatEvent{Meta: Meta{ServerID: "1"}, Chat: models.Chat{Origin: "Bob", Message: "hi"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{`"server_id"`, `"origin"`, `"message"`} {
+		if !strings.Contains(string(data), key) {
+			t.Errorf("%s has no %s key", data, key)
+		}
+	}
+	if strings.Contains(string(data), `"Origin"`) {
+		t.Errorf("%s mixes Go field names into the payload", data)
+	}
+}
@@ -0,0 +1,46 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+var (
+	decodersMu sync.RWMutex
+	decoders   = map[Type]func([]byte) (Event, error){}
+)
+
+// Register makes Decode aware of the event struct T
+func Register[T Event]() {
+	var zero T
+	decodersMu.Lock()
+	defer decodersMu.Unlock()
+	decoders[zero.Type()] = func(data []byte) (Event, error) {
+		var e T
+		if err := json.Unmarshal(data, &e); err != nil {
+			return nil, err
+		}
+		return e, nil
+	}
+}
+
+// Decode unmarshals data into the registered struct for t
+func Decode(t Type, data []byte) (Event, error) {
+	decodersMu.RLock()
+	dec, ok := decoders[t]
+	decodersMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown event type %q", t)
+	}
+	return dec(data)
+}
+
+func init() {
+	Register[ChatEvent]()
+	Register[JoinEvent]()
+	Register[LeaveEvent]()
+	Register[MapChangeEvent]()
+	Register[ReportEvent]()
+	Register[AuditEvent]()
+}
@@ -0,0 +1,81 @@
+package events
+
+import (
+	"time"
+
+	"github.com/Yallamaztar/go-iw4m/models"
+)
+
+type Type string
+
+const (
+	TypeChat      Type = "chat"
+	TypeJoin      Type = "join"
+	TypeLeave     Type = "leave"
+	TypeMapChange Type = "map_change"
+	TypeReport    Type = "report"
+	TypeAudit     Type = "audit"
+)
+
+// Event is implemented by every typed event published on a Bus
+type Event interface {
+	Type() Type
+	Server() string
+	Time() time.Time
+}
+
+// Meta holds the fields shared by all events
+type Meta struct {
+	ServerID  string    `json:"server_id"`
+	Timestamp time.Time `json:"time"`
+}
+
+func (m Meta) Server() string  { return m.ServerID }
+func (m Meta) Time() time.Time { return m.Timestamp }
+
+func newMeta(serverID string) Meta {
+	return Meta{ServerID: serverID, Timestamp: time.Now()}
+}
+
+type ChatEvent struct {
+	Meta
+	models.Chat
+}
+
+func (ChatEvent) Type() Type { return TypeChat }
+
+type JoinEvent struct {
+	Meta
+	Player models.Player `json:"player"`
+}
+
+func (JoinEvent) Type() Type { return TypeJoin }
+
+type LeaveEvent struct {
+	Meta
+	Player models.Player `json:"player"`
+}
+
+func (LeaveEvent) Type() Type { return TypeLeave }
+
+type MapChangeEvent struct {
+	Meta
+	Previous string `json:"previous"`
+	Current  string `json:"current"`
+}
+
+func (MapChangeEvent) Type() Type { return TypeMapChange }
+
+type ReportEvent struct {
+	Meta
+	Report models.Report `json:"report"`
+}
+
+func (ReportEvent) Type() Type { return TypeReport }
+
+type AuditEvent struct {
+	Meta
+	Entry models.AuditLog `json:"entry"`
+}
+
+func (AuditEvent) Type() Type { return TypeAudit }
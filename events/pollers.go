@@ -0,0 +1,201 @@
+package events
+
+import (
+	"context"
+
+	"github.com/Yallamaztar/go-iw4m/models"
+	"github.com/Yallamaztar/go-iw4m/server"
+)
+
+// Poller produces the events that happened since its previous call
+type Poller interface {
+	Poll(ctx context.Context) ([]Event, error)
+}
+
+type PollerFunc func(ctx context.Context) ([]Event, error)
+
+func (f PollerFunc) Poll(ctx context.Context) ([]Event, error) { return f(ctx) }
+
+// seen tracks a multiset of keys so entries that were already on the page
+// during the previous poll are not emitted again
+type seen map[string]int
+
+func diff[T any](prev seen, items []T, key func(T) string) ([]T, seen) {
+	next := make(seen, len(items))
+	var fresh []T
+	for _, item := range items {
+		k := key(item)
+		next[k]++
+		if prev[k] > 0 {
+			prev[k]--
+			continue
+		}
+		fresh = append(fresh, item)
+	}
+	return fresh, next
+}
+
+type ChatPoller struct {
+	Server *server.Server
+	prev   seen
+}
+
+// Constructor to create ChatPoller from Server instance
+func NewChatPoller(s *server.Server) *ChatPoller {
+	return &ChatPoller{Server: s}
+}
+
+func (p *ChatPoller) Poll(ctx context.Context) ([]Event, error) {
+	chat, err := p.Server.ReadChat()
+	if err != nil {
+		return nil, err
+	}
+
+	first := p.prev == nil
+	fresh, next := diff(p.prev, chat, func(c models.Chat) string { return c.Origin + "\x00" + c.Message })
+	p.prev = next
+	if first {
+		return nil, nil
+	}
+
+	events := make([]Event, 0, len(fresh))
+	for _, c := range fresh {
+		events = append(events, ChatEvent{Meta: newMeta(p.Server.Wrapper.ServerID), Chat: c})
+	}
+	return events, nil
+}
+
+type PlayerPoller struct {
+	Server *server.Server
+	prev   map[string]models.Player
+}
+
+// Constructor to create PlayerPoller from Server instance
+func NewPlayerPoller(s *server.Server) *PlayerPoller {
+	return &PlayerPoller{Server: s}
+}
+
+func (p *PlayerPoller) Poll(ctx context.Context) ([]Event, error) {
+	players, err := p.Server.GetPlayersContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	current := make(map[string]models.Player, len(players))
+	for _, pl := range players {
+		current[pl.XUID] = pl
+	}
+
+	first := p.prev == nil
+	prev := p.prev
+	p.prev = current
+	if first {
+		return nil, nil
+	}
+
+	var events []Event
+	serverID := p.Server.Wrapper.ServerID
+	for id, pl := range current {
+		if _, ok := prev[id]; !ok {
+			events = append(events, JoinEvent{Meta: newMeta(serverID), Player: pl})
+		}
+	}
+	for id, pl := range prev {
+		if _, ok := current[id]; !ok {
+			events = append(events, LeaveEvent{Meta: newMeta(serverID), Player: pl})
+		}
+	}
+	return events, nil
+}
+
+type MapPoller struct {
+	Server  *server.Server
+	current string
+}
+
+// Constructor to create MapPoller from Server instance
+func NewMapPoller(s *server.Server) *MapPoller {
+	return &MapPoller{Server: s}
+}
+
+func (p *MapPoller) Poll(ctx context.Context) ([]Event, error) {
+	name, err := p.Server.MapName()
+	if err != nil {
+		return nil, err
+	}
+
+	prev := p.current
+	p.current = name
+	if prev == "" || prev == name {
+		return nil, nil
+	}
+	return []Event{MapChangeEvent{Meta: newMeta(p.Server.Wrapper.ServerID), Previous: prev, Current: name}}, nil
+}
+
+type ReportPoller struct {
+	Server *server.Server
+	prev   seen
+}
+
+// Constructor to create ReportPoller from Server instance
+func NewReportPoller(s *server.Server) *ReportPoller {
+	return &ReportPoller{Server: s}
+}
+
+func (p *ReportPoller) Poll(ctx context.Context) ([]Event, error) {
+	reports, err := p.Server.Reports()
+	if err != nil {
+		return nil, err
+	}
+
+	first := p.prev == nil
+	fresh, next := diff(p.prev, reports, func(r models.Report) string {
+		return r.Origin + "\x00" + r.Target + "\x00" + r.Reason + "\x00" + r.Timestamp
+	})
+	p.prev = next
+	if first {
+		return nil, nil
+	}
+
+	events := make([]Event, 0, len(fresh))
+	for _, r := range fresh {
+		events = append(events, ReportEvent{Meta: newMeta(p.Server.Wrapper.ServerID), Report: r})
+	}
+	return events, nil
+}
+
+type AuditPoller struct {
+	Server *server.Server
+	Count  int
+	prev   seen
+}
+
+// Constructor to create AuditPoller from Server instance
+func NewAuditPoller(s *server.Server, count int) *AuditPoller {
+	if count <= 0 {
+		count = 25
+	}
+	return &AuditPoller{Server: s, Count: count}
+}
+
+func (p *AuditPoller) Poll(ctx context.Context) ([]Event, error) {
+	logs, err := p.Server.AuditLogs(p.Count)
+	if err != nil {
+		return nil, err
+	}
+
+	first := p.prev == nil
+	fresh, next := diff(p.prev, logs, func(a models.AuditLog) string {
+		return a.Type + "\x00" + a.Origin + "\x00" + a.Target + "\x00" + a.Data + "\x00" + a.Time
+	})
+	p.prev = next
+	if first {
+		return nil, nil
+	}
+
+	events := make([]Event, 0, len(fresh))
+	for _, a := range fresh {
+		events = append(events, AuditEvent{Meta: newMeta(p.Server.Wrapper.ServerID), Entry: a})
+	}
+	return events, nil
+}
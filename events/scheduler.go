@@ -0,0 +1,102 @@
+package events
+
+import (
+	"context"
+	"time"
+
+	"github.com/Yallamaztar/go-iw4m/server"
+	"github.com/Yallamaztar/go-iw4m/wrapper"
+)
+
+const DefaultInterval = 5 * time.Second
+
+type scheduled struct {
+	poller   Poller
+	interval time.Duration
+	next     time.Time
+}
+
+// Scheduler drives a set of pollers sequentially off a single wrapper and
+// publishes whatever they produce on Bus
+type Scheduler struct {
+	Wrapper *wrapper.IW4MWrapper
+	Bus     *Bus
+	OnError func(Poller, error)
+	jobs    []*scheduled
+}
+
+// Constructor to create Scheduler from IW4MWrapper instance
+func NewScheduler(w *wrapper.IW4MWrapper, bus *Bus) *Scheduler {
+	return &Scheduler{Wrapper: w, Bus: bus}
+}
+
+// Add registers p to be polled every interval
+func (s *Scheduler) Add(p Poller, interval time.Duration) *Scheduler {
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	s.jobs = append(s.jobs, &scheduled{poller: p, interval: interval})
+	return s
+}
+
+// AddDefaults registers the chat, player, map, report and audit pollers
+func (s *Scheduler) AddDefaults(interval time.Duration) *Scheduler {
+	srv := server.NewServer(s.Wrapper)
+	return s.
+		Add(NewChatPoller(srv), interval).
+		Add(NewPlayerPoller(srv), interval).
+		Add(NewMapPoller(srv), interval).
+		Add(NewReportPoller(srv), interval).
+		Add(NewAuditPoller(srv, 0), interval)
+}
+
+// Run polls until ctx is cancelled
+func (s *Scheduler) Run(ctx context.Context) error {
+	if len(s.jobs) == 0 {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	now := time.Now()
+	for _, j := range s.jobs {
+		j.next = now
+	}
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+		}
+
+		now := time.Now()
+		for _, j := range s.jobs {
+			if now.Before(j.next) {
+				continue
+			}
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+
+			events, err := j.poller.Poll(ctx)
+			if err != nil && s.OnError != nil {
+				s.OnError(j.poller, err)
+			}
+			for _, e := range events {
+				s.Bus.Publish(e)
+			}
+			j.next = now.Add(j.interval)
+		}
+
+		next := s.jobs[0].next
+		for _, j := range s.jobs[1:] {
+			if j.next.Before(next) {
+				next = j.next
+			}
+		}
+		timer.Reset(time.Until(next))
+	}
+}
@@ -0,0 +1,222 @@
+package journal
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/Yallamaztar/go-iw4m/events"
+)
+
+const (
+	DefaultMaxSize  = 10 << 20
+	DefaultMaxFiles = 5
+)
+
+// Record is a single NDJSON line in the journal
+type Record struct {
+	Type  events.Type     `json:"type"`
+	Event json.RawMessage `json:"event"`
+}
+
+// Writer appends events to Path, rotating to Path.1 ... Path.N once the
+// file grows past MaxSize
+type Writer struct {
+	Path     string
+	MaxSize  int64
+	MaxFiles int
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+// Constructor to create Writer appending to path
+func NewWriter(path string) (*Writer, error) {
+	w := &Writer{Path: path, MaxSize: DefaultMaxSize, MaxFiles: DefaultMaxFiles}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *Writer) open() error {
+	f, err := os.OpenFile(w.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	w.file = f
+	w.size = info.Size()
+	return nil
+}
+
+func (w *Writer) rotate() error {
+	if err := w.file.Close(); err != nil {
+		return err
+	}
+
+	os.Remove(fmt.Sprintf("%s.%d", w.Path, w.MaxFiles))
+	for i := w.MaxFiles - 1; i >= 1; i-- {
+		os.Rename(fmt.Sprintf("%s.%d", w.Path, i), fmt.Sprintf("%s.%d", w.Path, i+1))
+	}
+	if w.MaxFiles > 0 {
+		if err := os.Rename(w.Path, w.Path+".1"); err != nil {
+			return err
+		}
+	} else {
+		os.Remove(w.Path)
+	}
+	return w.open()
+}
+
+// Write appends e to the journal
+func (w *Writer) Write(e events.Event) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	line, err := json.Marshal(Record{Type: e.Type(), Event: data})
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file == nil {
+		return fmt.Errorf("journal is closed")
+	}
+
+	if w.MaxSize > 0 && w.size > 0 && w.size+int64(len(line)) > w.MaxSize {
+		if err := w.rotate(); err != nil {
+			return err
+		}
+	}
+
+	n, err := w.file.Write(line)
+	w.size += int64(n)
+	return err
+}
+
+// Attach journals every event published on bus. Events are never dropped,
+// so a slow disk applies backpressure to the publisher
+func (w *Writer) Attach(bus *events.Bus, onError func(error)) *events.Subscription {
+	return bus.Subscribe(func(e events.Event) {
+		if err := w.Write(e); err != nil && onError != nil {
+			onError(err)
+		}
+	}, events.SubscribeOptions{Policy: events.Block})
+}
+
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file == nil {
+		return nil
+	}
+	err := w.file.Close()
+	w.file = nil
+	return err
+}
+
+// Files returns the journal files for path from oldest to newest
+func Files(path string, maxFiles int) []string {
+	var files []string
+	for i := maxFiles; i >= 1; i-- {
+		name := fmt.Sprintf("%s.%d", path, i)
+		if _, err := os.Stat(name); err == nil {
+			files = append(files, name)
+		}
+	}
+	if _, err := os.Stat(path); err == nil {
+		files = append(files, path)
+	}
+	return files
+}
+
+// Read calls fn for every event in the given files, in order
+func Read(files []string, fn func(events.Event) error) error {
+	for _, name := range files {
+		if err := readFile(name, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readFile(name string, fn func(events.Event) error) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 4<<20)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var rec Record
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			return fmt.Errorf("%s:%d: %w", name, line, err)
+		}
+		e, err := events.Decode(rec.Type, rec.Event)
+		if err != nil {
+			return fmt.Errorf("%s:%d: %w", name, line, err)
+		}
+		if err := fn(e); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// Replayer feeds journaled events back through a bus
+type Replayer struct {
+	Bus *events.Bus
+	// Speed scales the original gaps between events: 1 is real time, 10 is
+	// ten times faster and 0 replays without waiting
+	Speed float64
+}
+
+// Constructor to create Replayer publishing on bus
+func NewReplayer(bus *events.Bus, speed float64) *Replayer {
+	return &Replayer{Bus: bus, Speed: speed}
+}
+
+// Replay publishes every event in files, preserving the recorded timing
+func (r *Replayer) Replay(ctx context.Context, files ...string) error {
+	var prev time.Time
+	return Read(files, func(e events.Event) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if r.Speed > 0 && !prev.IsZero() {
+			if gap := e.Time().Sub(prev); gap > 0 {
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case <-time.After(time.Duration(float64(gap) / r.Speed)):
+				}
+			}
+		}
+		prev = e.Time()
+
+		r.Bus.Publish(e)
+		return nil
+	})
+}
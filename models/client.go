@@ -0,0 +1,322 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Role mirrors IW4MAdmin's permission levels
+type Role int
+
+const (
+	RoleBanned        Role = -1
+	RoleUser          Role = 0
+	RoleFlagged       Role = 1
+	RoleTrusted       Role = 2
+	RoleModerator     Role = 3
+	RoleAdministrator Role = 4
+	RoleSeniorAdmin   Role = 5
+	RoleOwner         Role = 6
+	RoleCreator       Role = 7
+	RoleConsole       Role = 8
+)
+
+var roleNames = map[Role]string{
+	RoleBanned:        "Banned",
+	RoleUser:          "User",
+	RoleFlagged:       "Flagged",
+	RoleTrusted:       "Trusted",
+	RoleModerator:     "Moderator",
+	RoleAdministrator: "Administrator",
+	RoleSeniorAdmin:   "SeniorAdmin",
+	RoleOwner:         "Owner",
+	RoleCreator:       "Creator",
+	RoleConsole:       "Console",
+}
+
+func (r Role) String() string {
+	if name, ok := roleNames[r]; ok {
+		return name
+	}
+	return strconv.Itoa(int(r))
+}
+
+func (r Role) Level() int { return int(r) }
+
+// ParseRole accepts an IW4MAdmin permission name or level number
+func ParseRole(s string) (Role, error) {
+	s = strings.TrimSpace(s)
+	if n, err := strconv.Atoi(s); err == nil {
+		return Role(n), nil
+	}
+
+	key := strings.ToLower(strings.NewReplacer(" ", "", "_", "").Replace(s))
+	for role, name := range roleNames {
+		if strings.ToLower(name) == key {
+			return role, nil
+		}
+	}
+	switch key {
+	case "admin":
+		return RoleAdministrator, nil
+	case "senior", "senioradministrator":
+		return RoleSeniorAdmin, nil
+	}
+	return RoleUser, fmt.Errorf("unknown role %q", s)
+}
+
+// playerRoles maps the role labels produced by Server.GetPlayers, which are
+// derived from the webfront's level-color-N classes, to their levels
+var playerRoles = map[string]Role{
+	"banned":    RoleBanned,
+	"user":      RoleUser,
+	"flagged":   RoleFlagged,
+	"trusted":   RoleTrusted,
+	"admin":     Role(3),
+	"senior":    Role(4),
+	"moderator": Role(5),
+	"owner":     RoleOwner,
+	"creator":   RoleCreator,
+}
+
+// PlayerRole converts a Player.Role label into a Role, defaulting to RoleUser
+func PlayerRole(label string) Role {
+	if role, ok := playerRoles[strings.ToLower(label)]; ok {
+		return role
+	}
+	return RoleUser
+}
+
+func (r *Role) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || string(data) == "null" {
+		*r = RoleUser
+		return nil
+	}
+
+	if data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		role, err := ParseRole(s)
+		if err != nil {
+			return err
+		}
+		*r = role
+		return nil
+	}
+
+	var n Number
+	if err := n.UnmarshalJSON(data); err != nil {
+		return err
+	}
+	*r = Role(n.Int())
+	return nil
+}
+
+func (r Role) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.String())
+}
+
+// Duration decodes either a number of seconds or a .NET TimeSpan string
+// ("d.hh:mm:ss.fffffff")
+type Duration time.Duration
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || string(data) == "null" {
+		*d = 0
+		return nil
+	}
+
+	if data[0] != '"' {
+		var n Number
+		if err := n.UnmarshalJSON(data); err != nil {
+			return err
+		}
+		*d = Duration(n.Float() * float64(time.Second))
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	v, err := ParseTimeSpan(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).Seconds())
+}
+
+func (d Duration) Duration() time.Duration { return time.Duration(d) }
+
+// ParseTimeSpan parses a .NET TimeSpan such as "1.02:03:04.5" or "02:03:04"
+func ParseTimeSpan(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, nil
+	}
+	if secs, err := strconv.ParseFloat(s, 64); err == nil {
+		return time.Duration(secs * float64(time.Second)), nil
+	}
+
+	neg := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(s, "-")
+
+	var days int
+	parts := strings.Split(s, ":")
+	if len(parts) != 3 {
+		return 0, fmt.Errorf("invalid timespan %q", s)
+	}
+	if i := strings.Index(parts[0], "."); i >= 0 {
+		d, err := strconv.Atoi(parts[0][:i])
+		if err != nil {
+			return 0, fmt.Errorf("invalid timespan %q", s)
+		}
+		days = d
+		parts[0] = parts[0][i+1:]
+	}
+
+	hours, err1 := strconv.Atoi(parts[0])
+	minutes, err2 := strconv.Atoi(parts[1])
+	seconds, err3 := strconv.ParseFloat(parts[2], 64)
+	if err1 != nil || err2 != nil || err3 != nil {
+		return 0, fmt.Errorf("invalid timespan %q", s)
+	}
+
+	d := time.Duration(days)*24*time.Hour +
+		time.Duration(hours)*time.Hour +
+		time.Duration(minutes)*time.Minute +
+		time.Duration(seconds*float64(time.Second))
+	if neg {
+		d = -d
+	}
+	return d, nil
+}
+
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.9999999",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+}
+
+// ParseTime parses the timestamp formats used by the IW4MAdmin API
+func ParseTime(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q", s)
+}
+
+type ClientInfo struct {
+	ClientID            int       `json:"clientId"`
+	XUID                string    `json:"xuid"`
+	Name                string    `json:"name"`
+	Level               int       `json:"level"`
+	Role                Role      `json:"role"`
+	State               string    `json:"state"`
+	Connected           bool      `json:"connected"`
+	FirstConnection     time.Time `json:"firstConnection"`
+	LastConnection      time.Time `json:"lastConnection"`
+	TotalConnectionTime Duration  `json:"totalConnectionTime"`
+}
+
+func (c *ClientInfo) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		ClientID            Number          `json:"clientId"`
+		XUID                json.RawMessage `json:"xuid"`
+		NetworkID           json.RawMessage `json:"networkId"`
+		Name                string          `json:"name"`
+		Level               Role            `json:"level"`
+		State               json.RawMessage `json:"state"`
+		Online              *bool           `json:"online"`
+		Connected           *bool           `json:"connected"`
+		FirstConnection     string          `json:"firstConnection"`
+		LastConnection      string          `json:"lastConnection"`
+		TotalConnectionTime Duration        `json:"totalConnectionTime"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	*c = ClientInfo{
+		ClientID:            raw.ClientID.Int(),
+		XUID:                rawString(raw.XUID),
+		Name:                raw.Name,
+		Level:               raw.Level.Level(),
+		Role:                raw.Level,
+		State:               rawString(raw.State),
+		TotalConnectionTime: raw.TotalConnectionTime,
+	}
+	if c.XUID == "" {
+		c.XUID = rawString(raw.NetworkID)
+	}
+
+	switch {
+	case raw.Connected != nil:
+		c.Connected = *raw.Connected
+	case raw.Online != nil:
+		c.Connected = *raw.Online
+	default:
+		state := strings.ToLower(c.State)
+		c.Connected = state == "connected" || state == "1"
+	}
+
+	if raw.FirstConnection != "" {
+		c.FirstConnection, _ = ParseTime(raw.FirstConnection)
+	}
+	if raw.LastConnection != "" {
+		c.LastConnection, _ = ParseTime(raw.LastConnection)
+	}
+	return nil
+}
+
+// rawString returns a JSON string or number as plain text
+func rawString(data json.RawMessage) string {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || string(data) == "null" {
+		return ""
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		return s
+	}
+	return string(data)
+}
+
+// UnmarshalJSON accepts client IDs and XUIDs as numbers or strings and
+// levels as numbers or permission names
+func (p *PlayerClientInfo) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		XUID     json.RawMessage `json:"xuid"`
+		Name     string          `json:"name"`
+		ClientID json.RawMessage `json:"clientId"`
+		Level    Role            `json:"level"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	*p = PlayerClientInfo{
+		XUID:     rawString(raw.XUID),
+		Name:     raw.Name,
+		ClientID: rawString(raw.ClientID),
+		Level:    raw.Level.Level(),
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseTimeSpan(t *testing.T) {
+	tests := []struct {
+		in   string
+		want time.Duration
+	}{
+		{"", 0},
+		{"90", 90 * time.Second},
+		{"01:02:03", time.Hour + 2*time.Minute + 3*time.Second},
+		{"2.03:00:00", 51 * time.Hour},
+		{"00:00:01.5", 1500 * time.Millisecond},
+		{"-00:10:00", -10 * time.Minute},
+	}
+	for _, tt := range tests {
+		got, err := ParseTimeSpan(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseTimeSpan(%q) = %v, %v; want %v", tt.in, got, err, tt.want)
+		}
+	}
+	for _, in := range []string{"1:2", "x.01:00:00", "aa:bb:cc"} {
+		if _, err := ParseTimeSpan(in); err == nil {
+			t.Errorf("ParseTimeSpan(%q) succeeded, want error", in)
+		}
+	}
+}
+
+func TestParseRole(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Role
+	}{
+		{"Owner", RoleOwner},
+		{"senior admin", RoleSeniorAdmin},
+		{"SeniorAdmin", RoleSeniorAdmin},
+		{"admin", RoleAdministrator},
+		{"-1", RoleBanned},
+		{"2", RoleTrusted},
+	}
+	for _, tt := range tests {
+		got, err := ParseRole(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseRole(%q) = %v, %v; want %v", tt.in, got, err, tt.want)
+		}
+	}
+	if _, err := ParseRole("overlord"); err == nil {
+		t.Error("ParseRole(\"overlord\") succeeded, want error")
+	}
+}
+
+func TestPlayerRole(t *testing.T) {
+	tests := []struct {
+		label string
+		want  Role
+	}{
+		{"banned", RoleBanned},
+		{"Trusted", RoleTrusted},
+		{"moderator", Role(5)},
+		{"unknown", RoleUser},
+	}
+	for _, tt := range tests {
+		if got := PlayerRole(tt.label); got != tt.want {
+			t.Errorf("PlayerRole(%q) = %v; want %v", tt.label, got, tt.want)
+		}
+	}
+}
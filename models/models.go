@@ -1,5 +1,7 @@
 package models
 
+import "time"
+
 type CommandHelp struct {
 	Alias          string `json:"alias"`
 	Description    string `json:"description"`
@@ -15,10 +17,10 @@ type HelpCategory struct {
 type Help map[string]HelpCategory
 
 type Report struct {
-	Origin    string
-	Reason    string
-	Target    string
-	Timestamp string
+	Origin    string `json:"origin"`
+	Reason    string `json:"reason"`
+	Target    string `json:"target"`
+	Timestamp string `json:"timestamp"`
 }
 
 type ServerID struct {
@@ -27,15 +29,15 @@ type ServerID struct {
 }
 
 type Chat struct {
-	Origin  string
-	Message string
+	Origin  string `json:"origin"`
+	Message string `json:"message"`
 }
 
 type Player struct {
-	Role string
-	Name string
-	XUID string
-	URL  string
+	Role string `json:"role"`
+	Name string `json:"name"`
+	XUID string `json:"xuid"`
+	URL  string `json:"url"`
 }
 
 type RecentClient struct {
@@ -47,12 +49,12 @@ type RecentClient struct {
 }
 
 type AuditLog struct {
-	Type   string
-	Origin string
-	Href   string
-	Target string
-	Data   string
-	Time   string
+	Type   string `json:"type"`
+	Origin string `json:"origin"`
+	Href   string `json:"href"`
+	Target string `json:"target"`
+	Data   string `json:"data"`
+	Time   string `json:"time"`
 }
 
 type Admin struct {
@@ -102,7 +104,8 @@ type WeaponUsage struct {
 }
 
 type PlayerResponse struct {
-	Clients []PlayerClientInfo `json:"clients"`
+	TotalFound int                `json:"totalFoundClients"`
+	Clients    []PlayerClientInfo `json:"clients"`
 }
 type PlayerClientInfo struct {
 	XUID     string `json:"xuid"`
@@ -110,3 +113,151 @@ type PlayerClientInfo struct {
 	ClientID string `json:"clientId"`
 	Level    int    `json:"level"`
 }
+
+type Pagination struct {
+	Offset int `json:"offset"`
+	Count  int `json:"count"`
+	// Total is the number of matches reported by the server, 0 if unknown
+	Total   int  `json:"total"`
+	HasMore bool `json:"has_more"`
+}
+
+type ClientStats struct {
+	ClientID string        `json:"client_id"`
+	Servers  []ServerStats `json:"servers"`
+}
+
+type ServerStats struct {
+	Name               string `json:"name"`
+	ServerName         string `json:"serverName"`
+	ServerGame         string `json:"serverGame"`
+	Ranking            Number `json:"ranking"`
+	Kills              Number `json:"kills"`
+	Deaths             Number `json:"deaths"`
+	KDR                Number `json:"kdr"`
+	ScorePerMinute     Number `json:"scorePerMinute"`
+	Skill              Number `json:"skill"`
+	Performance        Number `json:"performance"`
+	TotalSecondsPlayed Number `json:"totalSecondsPlayed"`
+	LastPlayed         string `json:"lastPlayed"`
+}
+
+// KillDeathRatio returns the reported KDR, computing it when the server
+// did not send one
+func (s ServerStats) KillDeathRatio() float64 {
+	if s.KDR != 0 {
+		return s.KDR.Float()
+	}
+	if s.Deaths == 0 {
+		return s.Kills.Float()
+	}
+	return s.Kills.Float() / s.Deaths.Float()
+}
+
+func (s ServerStats) TimePlayed() time.Duration {
+	return time.Duration(s.TotalSecondsPlayed.Float() * float64(time.Second))
+}
+
+type Alias struct {
+	Name string `json:"name"`
+	Date string `json:"date"`
+}
+
+type IPEntry struct {
+	Address string `json:"address"`
+	Date    string `json:"date"`
+}
+
+type PenaltySummary struct {
+	Warnings int `json:"warnings"`
+	Kicks    int `json:"kicks"`
+	TempBans int `json:"temp_bans"`
+	Bans     int `json:"bans"`
+	Flags    int `json:"flags"`
+}
+
+type Profile struct {
+	ClientID      string         `json:"client_id"`
+	Name          string         `json:"name"`
+	GUID          string         `json:"guid"`
+	Level         string         `json:"level"`
+	Role          Role           `json:"role"`
+	FirstSeen     string         `json:"first_seen"`
+	LastSeen      string         `json:"last_seen"`
+	PlayTime      string         `json:"play_time"`
+	CurrentServer string         `json:"current_server"`
+	Aliases       []Alias        `json:"aliases"`
+	IPs           []IPEntry      `json:"ips"`
+	Penalties     PenaltySummary `json:"penalties"`
+	Meta          []StatEntry    `json:"meta"`
+}
+
+type PenaltyType string
+
+const (
+	PenaltyWarning PenaltyType = "Warning"
+	PenaltyKick    PenaltyType = "Kick"
+	PenaltyTempBan PenaltyType = "TempBan"
+	PenaltyBan     PenaltyType = "Ban"
+	PenaltyFlag    PenaltyType = "Flag"
+	PenaltyUnban   PenaltyType = "Unban"
+	PenaltyUnflag  PenaltyType = "Unflag"
+	PenaltyAny     PenaltyType = "Any"
+)
+
+type Penalty struct {
+	Type       PenaltyType `json:"type"`
+	Offender   string      `json:"offender"`
+	OffenderID string      `json:"offender_id"`
+	Punisher   string      `json:"punisher"`
+	PunisherID string      `json:"punisher_id"`
+	Reason     string      `json:"reason"`
+	Issued     string      `json:"issued"`
+	Expires    string      `json:"expires"`
+	Active     bool        `json:"active"`
+}
+
+type PenaltyFilter struct {
+	Type          PenaltyType
+	Offset        int
+	Count         int
+	HideAutomated bool
+}
+
+type PenaltyPage struct {
+	Penalties []Penalty `json:"penalties"`
+	Offset    int       `json:"offset"`
+	Count     int       `json:"count"`
+	HasMore   bool      `json:"has_more"`
+}
+
+type AuditLogPage struct {
+	Entries []AuditLog `json:"entries"`
+	Offset  int        `json:"offset"`
+	HasMore bool       `json:"has_more"`
+}
+
+type ChatMessage struct {
+	ClientID string `json:"client_id"`
+	Name     string `json:"name"`
+	Server   string `json:"server"`
+	Message  string `json:"message"`
+	Time     string `json:"time"`
+}
+
+// PageIterator tracks paging state across calls; Done is set once a call
+// returns fewer than Count results
+type PageIterator struct {
+	Offset int
+	Count  int
+	Done   bool
+}
+
+type MessageFilter struct {
+	ServerID string
+	ClientID string
+	After    string
+	Before   string
+	Offset   int
+	Count    int
+}
@@ -0,0 +1,97 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Number decodes JSON numbers as well as numeric strings such as "1,234"
+// or "1,5", since different IW4MAdmin versions serialize stats either way
+type Number float64
+
+func (n *Number) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || string(data) == "null" {
+		*n = 0
+		return nil
+	}
+
+	if data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		v, mult := NormalizeNumber(s)
+		if v == "" || v == "-" {
+			*n = 0
+			return nil
+		}
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return fmt.Errorf("invalid number %q", s)
+		}
+		*n = Number(f * mult)
+		return nil
+	}
+
+	f, err := strconv.ParseFloat(string(data), 64)
+	if err != nil {
+		return fmt.Errorf("invalid number %s", data)
+	}
+	*n = Number(f)
+	return nil
+}
+
+func (n Number) Int() int       { return int(n) }
+func (n Number) Float() float64 { return float64(n) }
+
+var suffixes = map[string]float64{
+	"k": 1e3,
+	"m": 1e6,
+	"b": 1e9,
+}
+
+// NormalizeNumber turns a display number such as "1,234.5", "1.234,5" or
+// "1.2k" into a string strconv.ParseFloat accepts and the multiplier of its
+// suffix. Both "," and "." are accepted as either thousands or decimal
+// separators
+func NormalizeNumber(s string) (string, float64) {
+	s = strings.TrimSpace(s)
+	s = strings.NewReplacer(" ", "", " ", "", " ", "", "'", "", "+", "").Replace(s)
+
+	mult := 1.0
+	if n := len(s); n > 0 {
+		if m, ok := suffixes[strings.ToLower(s[n-1:])]; ok {
+			mult = m
+			s = s[:n-1]
+		}
+	}
+
+	commas := strings.Count(s, ",")
+	dots := strings.Count(s, ".")
+	switch {
+	case commas > 0 && dots > 0:
+		// the separator that comes last is the decimal one
+		if strings.LastIndex(s, ",") > strings.LastIndex(s, ".") {
+			s = strings.ReplaceAll(s, ".", "")
+			s = strings.Replace(s, ",", ".", 1)
+		} else {
+			s = strings.ReplaceAll(s, ",", "")
+		}
+	case commas > 1:
+		s = strings.ReplaceAll(s, ",", "")
+	case commas == 1:
+		i := strings.Index(s, ",")
+		if len(s)-i-1 == 3 && mult == 1 {
+			s = strings.ReplaceAll(s, ",", "")
+		} else {
+			s = strings.Replace(s, ",", ".", 1)
+		}
+	case dots > 1:
+		s = strings.ReplaceAll(s, ".", "")
+	}
+	return s, mult
+}
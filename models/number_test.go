@@ -0,0 +1,45 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestNumberUnmarshal(t *testing.T) {
+	tests := []struct {
+		in   string
+		want float64
+	}{
+		{`12`, 12},
+		{`1.5`, 1.5},
+		{`-3`, -3},
+		{`"42"`, 42},
+		{`"1,234"`, 1234},
+		{`"1,5"`, 1.5},
+		{`"1.234,5"`, 1234.5},
+		{`"1.2k"`, 1200},
+		{`" 7.25 "`, 7.25},
+		{`""`, 0},
+		{`"-"`, 0},
+		{`null`, 0},
+	}
+	for _, tt := range tests {
+		var n Number
+		if err := json.Unmarshal([]byte(tt.in), &n); err != nil || n.Float() != tt.want {
+			t.Errorf("Unmarshal(%s) = %v, %v; want %v", tt.in, n, err, tt.want)
+		}
+	}
+
+	for _, in := range []string{`"abc"`, `true`, `{}`} {
+		var n Number
+		if err := json.Unmarshal([]byte(in), &n); err == nil {
+			t.Errorf("Unmarshal(%s) succeeded, want error", in)
+		}
+	}
+}
+
+func TestNumberInt(t *testing.T) {
+	if got := Number(3.9).Int(); got != 3 {
+		t.Errorf("Number(3.9).Int() = %d; want 3", got)
+	}
+}
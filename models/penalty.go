@@ -0,0 +1,45 @@
+package models
+
+import "strings"
+
+// ParsePenaltyType normalizes the penalty labels shown on the webfront
+// ("Banned", "Temp Ban", "Warned", ...)
+func ParsePenaltyType(s string) PenaltyType {
+	k := strings.ToLower(strings.Join(strings.Fields(s), ""))
+	switch {
+	case strings.Contains(k, "unban"):
+		return PenaltyUnban
+	case strings.Contains(k, "unflag"):
+		return PenaltyUnflag
+	case strings.Contains(k, "tempban"), strings.Contains(k, "temporary"):
+		return PenaltyTempBan
+	case strings.Contains(k, "ban"):
+		return PenaltyBan
+	case strings.Contains(k, "kick"):
+		return PenaltyKick
+	case strings.Contains(k, "warn"):
+		return PenaltyWarning
+	case strings.Contains(k, "flag"):
+		return PenaltyFlag
+	}
+	return PenaltyType(strings.TrimSpace(s))
+}
+
+// PenaltyActive guesses whether a penalty is still in effect from its type
+// and the expiry text shown next to it
+func PenaltyActive(t PenaltyType, expires string) bool {
+	e := strings.ToLower(expires)
+	switch {
+	case strings.Contains(e, "expired"), strings.Contains(e, "revoked"):
+		return false
+	case strings.Contains(e, "remaining"), strings.Contains(e, "left"):
+		return true
+	}
+	switch t {
+	case PenaltyBan, PenaltyFlag:
+		return true
+	case PenaltyTempBan:
+		return e != ""
+	}
+	return false
+}
@@ -0,0 +1,116 @@
+package namepolicy
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/Yallamaztar/go-iw4m/commands"
+	"github.com/Yallamaztar/go-iw4m/names"
+	"github.com/Yallamaztar/go-iw4m/stats"
+)
+
+// Config is the JSON policy file
+type Config struct {
+	// Patterns are case-insensitive regexes matched against the name with
+	// color codes stripped and against its normalized form
+	Patterns []string `json:"patterns"`
+	// Words are banned if they appear anywhere in the normalized name
+	Words     []string `json:"words"`
+	MinLength int      `json:"min_length"`
+	// MaxColorCodes limits ^N codes in a name; 0 disables the check
+	MaxColorCodes int `json:"max_color_codes"`
+	// Impersonation is the similarity (0-1) at which a name is treated as
+	// a copy of a staff member's; 0 uses DefaultImpersonation, < 0 disables
+	Impersonation float64 `json:"impersonation"`
+	// ExemptRole and up may use any name
+	ExemptRole commands.Exemption `json:"exempt_role"`
+	// Actions escalate on each join with a bad name; tell, kick or ban
+	Actions []commands.Action `json:"actions"`
+	// Grace is how long a told player has to rename before the next step
+	Grace string `json:"grace"`
+	// ResetAfter is how long a player has to keep a clean name before a
+	// bad one starts at the first action again
+	ResetAfter string `json:"reset_after"`
+	DryRun     bool   `json:"dry_run"`
+
+	patterns   []*regexp.Regexp
+	words      []string
+	grace      time.Duration
+	resetAfter time.Duration
+}
+
+const DefaultImpersonation = 0.85
+
+// LoadConfig reads a JSON name policy
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := cfg.Compile(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &cfg, nil
+}
+
+// Compile prepares the patterns and word list and fills in defaults
+func (c *Config) Compile() error {
+	c.patterns = nil
+	for _, p := range c.Patterns {
+		re, err := regexp.Compile("(?i)" + p)
+		if err != nil {
+			return err
+		}
+		c.patterns = append(c.patterns, re)
+	}
+
+	c.words = nil
+	for _, w := range c.Words {
+		if n := strings.ReplaceAll(names.Normalize(w, names.Options{}), " ", ""); n != "" {
+			c.words = append(c.words, n)
+		}
+	}
+
+	if c.Impersonation == 0 {
+		c.Impersonation = DefaultImpersonation
+	}
+
+	if len(c.Actions) == 0 {
+		c.Actions = []commands.Action{{Kind: commands.ActionTell}, {Kind: commands.ActionKick}}
+	}
+	for _, a := range c.Actions {
+		switch a.Kind {
+		case commands.ActionLog, commands.ActionTell, commands.ActionKick, commands.ActionBan:
+		default:
+			return fmt.Errorf("action must be log, tell, kick or ban, not %s", a)
+		}
+	}
+
+	c.grace = 2 * time.Minute
+	if c.Grace != "" {
+		d, err := stats.ParseDuration(c.Grace)
+		if err != nil {
+			return err
+		}
+		c.grace = d
+	}
+
+	c.resetAfter = commands.DefaultResetAfter
+	if c.ResetAfter != "" {
+		d, err := stats.ParseDuration(c.ResetAfter)
+		if err != nil {
+			return err
+		}
+		c.resetAfter = d
+	}
+	return nil
+}
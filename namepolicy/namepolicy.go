@@ -0,0 +1,346 @@
+package namepolicy
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/Yallamaztar/go-iw4m/commands"
+	"github.com/Yallamaztar/go-iw4m/events"
+	"github.com/Yallamaztar/go-iw4m/models"
+	"github.com/Yallamaztar/go-iw4m/names"
+	"github.com/Yallamaztar/go-iw4m/server"
+	"github.com/Yallamaztar/go-iw4m/utils"
+	"github.com/Yallamaztar/go-iw4m/wrapper"
+)
+
+type Violation string
+
+const (
+	ViolationPattern       Violation = "pattern"
+	ViolationLength        Violation = "length"
+	ViolationColor         Violation = "color"
+	ViolationImpersonation Violation = "impersonation"
+)
+
+// adminTTL is how long the Admins() list is reused between joins
+const adminTTL = 10 * time.Minute
+
+type Finding struct {
+	Violation Violation `json:"violation"`
+	Reason    string    `json:"reason"`
+}
+
+// Decision is the outcome of one join with a name that breaks the policy
+type Decision struct {
+	Time     time.Time       `json:"time"`
+	Player   models.Player   `json:"player"`
+	Findings []Finding       `json:"findings"`
+	Offense  int             `json:"offense"`
+	Action   commands.Action `json:"action"`
+	Command  string          `json:"command"`
+	DryRun   bool            `json:"dry_run"`
+}
+
+// pending is a player who was told to rename and is waiting out the grace
+// period. step counts the rechecks since their join
+type pending struct {
+	player  models.Player
+	offense int
+	step    int
+	timer   *time.Timer
+}
+
+type Enforcer struct {
+	Server  *server.Server
+	Config  *Config
+	Matcher *names.Matcher
+	// OnDecision is called for every violation, including dry-run ones
+	OnDecision func(Decision)
+
+	offenses *commands.Ladder
+
+	mu       sync.Mutex
+	admins   []models.Admin
+	adminsAt time.Time
+
+	waitMu  sync.Mutex
+	waiting map[string]*pending
+	closed  bool
+}
+
+// Constructor to create Enforcer from IW4MWrapper instance and a compiled config
+func NewEnforcer(w *wrapper.IW4MWrapper, cfg *Config) *Enforcer {
+	return &Enforcer{
+		Server:   server.NewServer(w),
+		Config:   cfg,
+		Matcher:  names.NewMatcher(names.Options{StripClanTags: true}),
+		offenses: commands.NewLadder(cfg.resetAfter),
+		waiting:  make(map[string]*pending),
+	}
+}
+
+// Attach checks every player published in a join event and drops the
+// pending recheck of players who leave
+func (e *Enforcer) Attach(bus *events.Bus) *events.Subscription {
+	return bus.Subscribe(func(ev events.Event) {
+		switch ev := ev.(type) {
+		case events.JoinEvent:
+			e.Enforce(ev.Player)
+		case events.LeaveEvent:
+			e.forget(playerKey(ev.Player))
+		}
+	}, events.SubscribeOptions{Types: []events.Type{events.TypeJoin, events.TypeLeave}})
+}
+
+// Close cancels every pending recheck; later violations are still acted
+// on but never rechecked
+func (e *Enforcer) Close() {
+	e.waitMu.Lock()
+	defer e.waitMu.Unlock()
+
+	e.closed = true
+	for key, w := range e.waiting {
+		w.timer.Stop()
+		delete(e.waiting, key)
+	}
+}
+
+func (e *Enforcer) staff() []models.Admin {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.admins == nil || time.Since(e.adminsAt) > adminTTL {
+		admins, err := e.Server.Admins("all", 0)
+		e.adminsAt = time.Now()
+		if err == nil {
+			e.admins = admins
+		}
+	}
+	return e.admins
+}
+
+func compact(s string) string {
+	return strings.ReplaceAll(s, " ", "")
+}
+
+// Check returns every way name breaks the policy, without acting on it
+func (e *Enforcer) Check(name string) []Finding {
+	return e.check(name, models.RoleUser)
+}
+
+// check is Check for a player with role, who may be the staff member
+// their name matches
+func (e *Enforcer) check(name string, role models.Role) []Finding {
+	cfg := e.Config
+	plain := strings.TrimSpace(utils.StripColorCodes(name))
+	normal := names.Normalize(name, names.Options{})
+
+	var findings []Finding
+	for _, re := range cfg.patterns {
+		if m := re.FindString(plain); m != "" {
+			findings = append(findings, Finding{ViolationPattern, fmt.Sprintf("name matches banned pattern %q", m)})
+		} else if m := re.FindString(normal); m != "" {
+			findings = append(findings, Finding{ViolationPattern, fmt.Sprintf("name matches banned pattern %q", m)})
+		}
+	}
+	for _, w := range cfg.words {
+		if strings.Contains(compact(normal), w) {
+			findings = append(findings, Finding{ViolationPattern, fmt.Sprintf("name contains %q", w)})
+		}
+	}
+
+	if n := len([]rune(plain)); n < cfg.MinLength {
+		findings = append(findings, Finding{ViolationLength, fmt.Sprintf("name is %d characters, minimum is %d", n, cfg.MinLength)})
+	}
+	// every color code is two bytes
+	if codes := (len(name) - len(utils.StripColorCodes(name))) / 2; cfg.MaxColorCodes > 0 && codes > cfg.MaxColorCodes {
+		findings = append(findings, Finding{ViolationColor, fmt.Sprintf("name has %d color codes, maximum is %d", codes, cfg.MaxColorCodes)})
+	}
+
+	if cfg.Impersonation > 0 {
+		if admin, score, ok := e.impersonates(name, role); ok {
+			findings = append(findings, Finding{ViolationImpersonation,
+				fmt.Sprintf("name resembles %s %s (%.0f%%)", admin.Role, utils.StripColorCodes(admin.Name), score*100)})
+		}
+	}
+	return findings
+}
+
+// impersonates finds the staff member whose name is closest to name, if
+// they are similar enough or name is theirs decorated with an unbracketed
+// tag or punctuation, e.g. "ADM|Mike" or "-=Mike=-" but not "Mikey". A
+// privileged player using exactly a staff name is taken to be that member
+func (e *Enforcer) impersonates(name string, role models.Role) (models.Admin, float64, bool) {
+	opts := e.Matcher.Options
+	query := compact(names.Normalize(name, opts))
+	if query == "" {
+		return models.Admin{}, 0, false
+	}
+
+	var best models.Admin
+	var bestScore float64
+	for _, a := range e.staff() {
+		target := compact(names.Normalize(a.Name, opts))
+		if target == "" || target == query && role >= models.RoleTrusted {
+			continue
+		}
+		score := names.Similarity(query, target)
+		if len(target) >= 4 && decorated(query, target) {
+			score = max(score, 0.95)
+		}
+		if score > bestScore {
+			best, bestScore = a, score
+		}
+	}
+	return best, bestScore, bestScore >= e.Config.Impersonation
+}
+
+// decorated reports whether query is target with only punctuation, or a
+// tag separated by punctuation, around it
+func decorated(query, target string) bool {
+	before, after, ok := strings.Cut(query, target)
+	if !ok {
+		return false
+	}
+	return isTag(before, true) && isTag(after, false)
+}
+
+// isTag accepts punctuation, optionally next to a short tag that is set
+// off from the name by it, like "adm|" before or "|adm" after
+func isTag(s string, before bool) bool {
+	if s == "" {
+		return true
+	}
+	word := strings.TrimFunc(s, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) })
+	if word == "" {
+		return true
+	}
+	if strings.ContainsFunc(word, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) }) {
+		return false
+	}
+	// the tag must be short and split from the name by punctuation
+	if len(word) > 5 {
+		return false
+	}
+	if before {
+		return !strings.HasSuffix(s, word)
+	}
+	return !strings.HasPrefix(s, word)
+}
+
+// Enforce checks p and applies the next step of their escalation ladder.
+// A player who was told to rename is checked again after the grace period
+// and, if still online under the same name, moved to the next action for
+// that join without counting another offense. It returns nil when the name
+// is fine or the player is exempt
+func (e *Enforcer) Enforce(p models.Player) *Decision {
+	key := playerKey(p)
+	e.forget(key)
+
+	role := models.PlayerRole(p.Role)
+	if e.Config.ExemptRole.Exempt(role) {
+		return nil
+	}
+	findings := e.check(p.Name, role)
+	if len(findings) == 0 {
+		return nil
+	}
+	return e.apply(p, findings, e.offenses.Next(key), 0)
+}
+
+func (e *Enforcer) apply(p models.Player, findings []Finding, offense, step int) *Decision {
+	action := commands.Escalate(e.Config.Actions, offense+step)
+	d := Decision{
+		Time:     time.Now(),
+		Player:   p,
+		Findings: findings,
+		Offense:  offense,
+		Action:   action,
+		Command:  command(action, p, findings[0].Reason),
+		DryRun:   e.Config.DryRun,
+	}
+	if !d.DryRun && d.Command != "" {
+		e.Server.SendCommand(d.Command)
+	}
+	if e.OnDecision != nil {
+		e.OnDecision(d)
+	}
+
+	if action.Kind == commands.ActionTell && offense+step < len(e.Config.Actions) {
+		e.wait(&pending{player: p, offense: offense, step: step})
+	}
+	return &d
+}
+
+func playerKey(p models.Player) string {
+	if p.XUID != "" {
+		return p.XUID
+	}
+	return strings.ToLower(p.Name)
+}
+
+func (e *Enforcer) wait(w *pending) {
+	e.waitMu.Lock()
+	defer e.waitMu.Unlock()
+
+	if e.closed {
+		return
+	}
+	key := playerKey(w.player)
+	if prev, ok := e.waiting[key]; ok {
+		prev.timer.Stop()
+	}
+	w.timer = time.AfterFunc(e.Config.grace, func() { e.recheck(key, w) })
+	e.waiting[key] = w
+}
+
+// forget cancels the recheck pending for key, if any
+func (e *Enforcer) forget(key string) {
+	e.waitMu.Lock()
+	defer e.waitMu.Unlock()
+
+	if w, ok := e.waiting[key]; ok {
+		w.timer.Stop()
+		delete(e.waiting, key)
+	}
+}
+
+func (e *Enforcer) recheck(key string, w *pending) {
+	e.waitMu.Lock()
+	if e.waiting[key] != w {
+		// cancelled, or replaced by a new join
+		e.waitMu.Unlock()
+		return
+	}
+	delete(e.waiting, key)
+	e.waitMu.Unlock()
+
+	players, err := e.Server.GetPlayers()
+	if err != nil {
+		return
+	}
+	for _, current := range players {
+		if current.XUID != w.player.XUID || current.Name != w.player.Name {
+			continue
+		}
+		role := models.PlayerRole(current.Role)
+		if e.Config.ExemptRole.Exempt(role) {
+			return
+		}
+		if findings := e.check(current.Name, role); len(findings) > 0 {
+			e.apply(current, findings, w.offense, w.step+1)
+		}
+		return
+	}
+}
+
+func command(a commands.Action, p models.Player, reason string) string {
+	message := fmt.Sprintf("Name not allowed: %s", reason)
+	if a.Kind == commands.ActionTell {
+		message = fmt.Sprintf("Please change your name: %s", reason)
+	}
+	return a.Command(commands.Target(p.Name, p.XUID), message)
+}
@@ -0,0 +1,125 @@
+package namepolicy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Yallamaztar/go-iw4m/commands"
+	"github.com/Yallamaztar/go-iw4m/models"
+	"github.com/Yallamaztar/go-iw4m/names"
+)
+
+func testEnforcer(t *testing.T) *Enforcer {
+	t.Helper()
+	cfg := &Config{DryRun: true}
+	if err := cfg.Compile(); err != nil {
+		t.Fatal(err)
+	}
+	return &Enforcer{
+		Config:  cfg,
+		Matcher: names.NewMatcher(names.Options{StripClanTags: true}),
+		admins: []models.Admin{
+			{Name: "^1Mike", Role: "Owner"},
+			{Name: "Sniper", Role: "Moderator"},
+			{Name: "Jo", Role: "Trusted"},
+		},
+		adminsAt: time.Now(),
+		offenses: commands.NewLadder(0),
+		waiting:  make(map[string]*pending),
+	}
+}
+
+func TestImpersonates(t *testing.T) {
+	e := testEnforcer(t)
+	tests := []struct {
+		name  string
+		role  models.Role
+		admin string
+		want  bool
+	}{
+		{"Mike", models.RoleUser, "^1Mike", true},
+		// Cyrillic і
+		{"^2Mіke", models.RoleUser, "^1Mike", true},
+		{"[ADM]Mike", models.RoleUser, "^1Mike", true},
+		{"ADM|Mike", models.RoleUser, "^1Mike", true},
+		{"-=Mike=-", models.RoleUser, "^1Mike", true},
+		{"Mike|ADM", models.RoleUser, "^1Mike", true},
+		{"Snipper", models.RoleUser, "Sniper", true},
+		{"Mikey", models.RoleUser, "", false},
+		{"MikeTyson", models.RoleUser, "", false},
+		{"Big Mike Energy", models.RoleUser, "", false},
+		{"Joey", models.RoleUser, "", false},
+		{"Bob", models.RoleUser, "", false},
+		// staff under their own name are not impersonating anyone
+		{"Mike", models.RoleOwner, "", false},
+		{"Sniper", models.RoleModerator, "", false},
+		// but a privileged player copying someone else still is
+		{"ADM|Mike", models.RoleTrusted, "^1Mike", true},
+	}
+	for _, tt := range tests {
+		admin, score, ok := e.impersonates(tt.name, tt.role)
+		if ok != tt.want || ok && admin.Name != tt.admin {
+			t.Errorf("impersonates(%q, %v) = %q, %.2f, %v; want %q, %v", tt.name, tt.role, admin.Name, score, ok, tt.admin, tt.want)
+		}
+	}
+}
+
+func TestDecorated(t *testing.T) {
+	tests := []struct {
+		query string
+		want  bool
+	}{
+		{"mike", true},
+		{"adm|mike", true},
+		{"mike|adm", true},
+		{"=mike=", true},
+		{"xx.mike.xx", true},
+		{"mikey", false},
+		{"bigmike", false},
+		{"miketyson", false},
+		{"clanname|mike", false},
+		{"a|b|mike", false},
+		{"sam", false},
+	}
+	for _, tt := range tests {
+		if got := decorated(tt.query, "mike"); got != tt.want {
+			t.Errorf("decorated(%q, %q) = %v; want %v", tt.query, "mike", got, tt.want)
+		}
+	}
+}
+
+func TestEnforce(t *testing.T) {
+	e := testEnforcer(t)
+	p := models.Player{Name: "ADM|Mike", XUID: "42", Role: "user"}
+
+	d := e.Enforce(p)
+	if d == nil || d.Offense != 1 || d.Action.Kind != commands.ActionTell {
+		t.Fatalf("first join = %+v; want offense 1, tell", d)
+	}
+	if _, ok := e.waiting["42"]; !ok {
+		t.Fatal("no recheck pending after tell")
+	}
+
+	// leaving cancels the recheck, rejoining is a new offense
+	e.forget("42")
+	if len(e.waiting) != 0 {
+		t.Fatal("recheck still pending after leave")
+	}
+	d = e.Enforce(p)
+	if d == nil || d.Offense != 2 || d.Action.Kind != commands.ActionKick {
+		t.Fatalf("second join = %+v; want offense 2, kick", d)
+	}
+
+	e.Enforce(models.Player{Name: "Mike", XUID: "7", Role: "user"})
+	e.Close()
+	if len(e.waiting) != 0 {
+		t.Fatal("recheck still pending after Close")
+	}
+	if d := e.Enforce(models.Player{Name: "Mike", XUID: "8", Role: "user"}); d == nil || len(e.waiting) != 0 {
+		t.Fatalf("join after Close = %+v with %d pending; want a decision and none pending", d, len(e.waiting))
+	}
+
+	if d := e.Enforce(models.Player{Name: "Mike", XUID: "1", Role: "owner"}); d != nil {
+		t.Errorf("exempt owner got %+v", d)
+	}
+}
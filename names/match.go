@@ -0,0 +1,173 @@
+package names
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/Yallamaztar/go-iw4m/models"
+	"github.com/Yallamaztar/go-iw4m/server"
+)
+
+const DefaultMinScore = 0.5
+
+// Distance returns the Levenshtein edit distance between a and b
+func Distance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) == 0 {
+		return len(rb)
+	}
+	if len(rb) == 0 {
+		return len(ra)
+	}
+
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
+
+// Similarity is 1 - Distance / longest length, so 1 means identical
+func Similarity(a, b string) float64 {
+	longest := max(len([]rune(a)), len([]rune(b)))
+	if longest == 0 {
+		return 1
+	}
+	return 1 - float64(Distance(a, b))/float64(longest)
+}
+
+type Match struct {
+	Name     string  `json:"name"`
+	Index    int     `json:"index"`
+	Score    float64 `json:"score"`
+	Distance int     `json:"distance"`
+}
+
+type Matcher struct {
+	Options  Options
+	MinScore float64
+}
+
+// Constructor to create Matcher with the given normalization options
+func NewMatcher(opts Options) *Matcher {
+	return &Matcher{Options: opts, MinScore: DefaultMinScore}
+}
+
+// Score rates candidate against query after normalization: 1 for equal
+// names, a bonus for prefix and substring hits, edit similarity otherwise
+func (m *Matcher) Score(query, candidate string) (float64, int) {
+	q := Normalize(query, m.Options)
+	c := Normalize(candidate, m.Options)
+	d := Distance(q, c)
+
+	switch {
+	case q == c:
+		return 1, 0
+	case q == "" || c == "":
+		return 0, d
+	}
+
+	score := Similarity(q, c)
+	ratio := float64(len([]rune(q))) / float64(len([]rune(c)))
+	if ratio > 1 {
+		ratio = 1 / ratio
+	}
+	switch {
+	case strings.HasPrefix(c, q):
+		score = max(score, 0.7+0.25*ratio)
+	case strings.Contains(c, q):
+		score = max(score, 0.6+0.25*ratio)
+	}
+	return score, d
+}
+
+// Rank scores every candidate and returns those above MinScore, best first
+func (m *Matcher) Rank(query string, candidates []string) []Match {
+	var matches []Match
+	for i, c := range candidates {
+		score, d := m.Score(query, c)
+		if score < m.MinScore {
+			continue
+		}
+		matches = append(matches, Match{Name: c, Index: i, Score: score, Distance: d})
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].Score > matches[j].Score })
+	return matches
+}
+
+// Best returns the highest ranked candidate
+func (m *Matcher) Best(query string, candidates []string) (Match, bool) {
+	matches := m.Rank(query, candidates)
+	if len(matches) == 0 {
+		return Match{}, false
+	}
+	return matches[0], true
+}
+
+type PlayerMatch struct {
+	Player models.Player `json:"player"`
+	Match
+}
+
+// Players ranks online players, e.g. from Server.GetPlayers
+func (m *Matcher) Players(query string, players []models.Player) []PlayerMatch {
+	candidates := make([]string, len(players))
+	for i, p := range players {
+		candidates[i] = p.Name
+	}
+
+	var matches []PlayerMatch
+	for _, match := range m.Rank(query, candidates) {
+		matches = append(matches, PlayerMatch{Player: players[match.Index], Match: match})
+	}
+	return matches
+}
+
+type ClientMatch struct {
+	Client models.PlayerClientInfo `json:"client"`
+	Match
+}
+
+// Clients ranks database search results, e.g. from Server.FindPlayers
+func (m *Matcher) Clients(query string, clients []models.PlayerClientInfo) []ClientMatch {
+	candidates := make([]string, len(clients))
+	for i, c := range clients {
+		candidates[i] = c.Name
+	}
+
+	var matches []ClientMatch
+	for _, match := range m.Rank(query, candidates) {
+		matches = append(matches, ClientMatch{Client: clients[match.Index], Match: match})
+	}
+	return matches
+}
+
+// Search queries the client database with the normalized query and ranks
+// the results, so color codes and clan tags in the query don't hide matches
+func (m *Matcher) Search(ctx context.Context, s *server.Server, query string, count int) ([]ClientMatch, error) {
+	opts := m.Options
+	opts.StripClanTags = true
+	q := Normalize(query, opts)
+	if q == "" {
+		q = query
+	}
+
+	clients, _, err := s.FindPlayers(ctx, server.FindOptions{Name: q, Count: count})
+	if err != nil {
+		return nil, err
+	}
+	return m.Clients(query, clients), nil
+}
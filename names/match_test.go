@@ -0,0 +1,70 @@
+package names
+
+import "testing"
+
+func TestDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"", "abc", 3},
+		{"kitten", "sitting", 3},
+		{"flaw", "lawn", 2},
+		{"same", "same", 0},
+		{"héllo", "hello", 1},
+	}
+	for _, tt := range tests {
+		if got := Distance(tt.a, tt.b); got != tt.want {
+			t.Errorf("Distance(%q, %q) = %d; want %d", tt.a, tt.b, got, tt.want)
+		}
+		if got := Distance(tt.b, tt.a); got != tt.want {
+			t.Errorf("Distance(%q, %q) = %d; want %d", tt.b, tt.a, got, tt.want)
+		}
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want float64
+	}{
+		{"", "", 1},
+		{"abcd", "abcd", 1},
+		{"abcd", "abce", 0.75},
+		{"ab", "", 0},
+	}
+	for _, tt := range tests {
+		if got := Similarity(tt.a, tt.b); got != tt.want {
+			t.Errorf("Similarity(%q, %q) = %v; want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestMatcherRank(t *testing.T) {
+	m := NewMatcher(Options{StripClanTags: true})
+	candidates := []string{"^1Yalla", "[CLAN] yallamaztar", "Someone", "yallama"}
+
+	best, ok := m.Best("yallamaztar", candidates)
+	if !ok || best.Index != 1 || best.Score != 1 {
+		t.Fatalf("Best = %+v, %v; want index 1 with score 1", best, ok)
+	}
+
+	ranked := m.Rank("yalla", candidates)
+	if len(ranked) != 3 {
+		t.Fatalf("Rank returned %d matches; want 3: %+v", len(ranked), ranked)
+	}
+	if ranked[0].Index != 0 {
+		t.Errorf("Rank[0] = %+v; want the exact match first", ranked[0])
+	}
+	for i := 1; i < len(ranked); i++ {
+		if ranked[i].Score > ranked[i-1].Score {
+			t.Errorf("Rank not sorted: %+v", ranked)
+		}
+	}
+
+	if _, ok := m.Best("zzzz", candidates); ok {
+		t.Error("Best(\"zzzz\") matched; want no match")
+	}
+}
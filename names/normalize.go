@@ -0,0 +1,80 @@
+package names
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/Yallamaztar/go-iw4m/utils"
+)
+
+type Options struct {
+	// StripClanTags removes leading/trailing [TAG], {TAG}, (TAG), <TAG> and |TAG|
+	StripClanTags bool
+	// KeepConfusables disables mapping of unicode look-alikes to ASCII
+	KeepConfusables bool
+}
+
+var clanTag = regexp.MustCompile(`^\s*(\[[^\]]*\]|\{[^}]*\}|\([^)]*\)|<[^>]*>|\|[^|]*\|)\s*|\s*(\[[^\]]*\]|\{[^}]*\}|\([^)]*\)|<[^>]*>|\|[^|]*\|)\s*$`)
+
+// confusables maps common look-alike characters to the ASCII letter they imitate
+var confusables = map[rune]rune{
+	// Cyrillic
+	'а': 'a', 'в': 'b', 'е': 'e', 'ё': 'e', 'к': 'k', 'м': 'm', 'н': 'h', 'о': 'o',
+	'р': 'p', 'с': 'c', 'т': 't', 'у': 'y', 'х': 'x', 'ѕ': 's', 'і': 'i', 'ї': 'i',
+	'ј': 'j', 'ԁ': 'd', 'ɡ': 'g', 'ԛ': 'q', 'ԝ': 'w',
+	// Greek
+	'α': 'a', 'β': 'b', 'ε': 'e', 'η': 'n', 'ι': 'i', 'κ': 'k', 'ν': 'v', 'ο': 'o',
+	'ρ': 'p', 'τ': 't', 'υ': 'u', 'χ': 'x', 'ω': 'w',
+	// Latin variants
+	'ı': 'i', 'ł': 'l', 'ø': 'o', 'đ': 'd', 'ħ': 'h', 'ŧ': 't', 'ß': 's',
+	'à': 'a', 'á': 'a', 'â': 'a', 'ã': 'a', 'ä': 'a', 'å': 'a',
+	'è': 'e', 'é': 'e', 'ê': 'e', 'ë': 'e',
+	'ì': 'i', 'í': 'i', 'î': 'i', 'ï': 'i',
+	'ò': 'o', 'ó': 'o', 'ô': 'o', 'õ': 'o', 'ö': 'o',
+	'ù': 'u', 'ú': 'u', 'û': 'u', 'ü': 'u',
+	'ý': 'y', 'ÿ': 'y', 'ñ': 'n', 'ç': 'c',
+}
+
+func fold(r rune, keepConfusables bool) (rune, bool) {
+	if r >= 0x200B && r <= 0x200F || r == 0xFEFF || r == 0x2060 {
+		return 0, false
+	}
+	if unicode.IsSpace(r) {
+		return ' ', true
+	}
+	// fullwidth forms
+	if r >= 0xFF01 && r <= 0xFF5E {
+		r = r - 0xFF01 + 0x21
+	}
+	r = unicode.ToLower(r)
+	if !keepConfusables {
+		if mapped, ok := confusables[r]; ok {
+			r = mapped
+		}
+	}
+	return r, true
+}
+
+// Normalize strips color codes, case-folds and maps look-alike characters
+// so names can be compared the way a player reads them
+func Normalize(name string, opts Options) string {
+	name = utils.StripColorCodes(name)
+	if opts.StripClanTags {
+		for {
+			stripped := clanTag.ReplaceAllString(name, "")
+			if stripped == name || strings.TrimSpace(stripped) == "" {
+				break
+			}
+			name = stripped
+		}
+	}
+
+	var b strings.Builder
+	for _, r := range name {
+		if f, ok := fold(r, opts.KeepConfusables); ok {
+			b.WriteRune(f)
+		}
+	}
+	return strings.Join(strings.Fields(b.String()), " ")
+}
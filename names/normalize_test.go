@@ -0,0 +1,28 @@
+package names
+
+import "testing"
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		in   string
+		opts Options
+		want string
+	}{
+		{"^1Red^7Name", Options{}, "redname"},
+		{"  Spaced   Out  ", Options{}, "spaced out"},
+		{"\u0410dmin", Options{}, "admin"},
+		{"\u0410dmin", Options{KeepConfusables: true}, "\u0430dmin"},
+		{"Ｗｉｄｅ", Options{}, "wide"},
+		{"zero\u200bwidth", Options{}, "zerowidth"},
+		{"[TAG] Player", Options{StripClanTags: true}, "player"},
+		{"Player |TAG|", Options{StripClanTags: true}, "player"},
+		{"{A}(B) Player", Options{StripClanTags: true}, "player"},
+		{"[TAG] Player", Options{}, "[tag] player"},
+		{"[ONLYTAG]", Options{StripClanTags: true}, "[onlytag]"},
+	}
+	for _, tt := range tests {
+		if got := Normalize(tt.in, tt.opts); got != tt.want {
+			t.Errorf("Normalize(%q, %+v) = %q; want %q", tt.in, tt.opts, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package player
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/Yallamaztar/go-iw4m/models"
+)
+
+const defaultChatPage = 50
+
+// ChatHistory returns the next page of a client's chat messages, newest
+// first, and advances it. A failed request is returned as an error and
+// leaves it where it was
+func (p *Player) ChatHistory(ctx context.Context, clientID string, it *models.PageIterator) ([]models.ChatMessage, error) {
+	if it == nil {
+		it = &models.PageIterator{}
+	}
+	if it.Done {
+		return nil, nil
+	}
+	if it.Count <= 0 {
+		it.Count = defaultChatPage
+	}
+
+	r, err := p.Wrapper.DoRequestContext(ctx, fmt.Sprintf("%s/Client/Meta/%s?offset=%d&count=%d&metaFilterType=ChatMessage",
+		p.Wrapper.BaseURL, clientID, it.Offset, it.Count))
+	if err != nil {
+		return nil, err
+	}
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(r))
+	if err != nil {
+		return nil, err
+	}
+
+	var messages []models.ChatMessage
+	entries := doc.Find("div.profile-meta-entry")
+	entries.Each(func(i int, entry *goquery.Selection) {
+		message := strings.TrimSpace(entry.Find("colorcode").Last().Text())
+		if message == "" {
+			return
+		}
+		messages = append(messages, models.ChatMessage{
+			ClientID: clientID,
+			Server:   cleanText(entry.Find(".text-muted, .server-name").First().Text()),
+			Message:  message,
+			Time:     cleanText(entry.Find("time, .profile-meta-title").First().Text()),
+		})
+	})
+
+	it.Offset += entries.Length()
+	it.Done = entries.Length() < it.Count
+	return messages, nil
+}
@@ -0,0 +1,289 @@
+package player
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/Yallamaztar/go-iw4m/models"
+	"github.com/Yallamaztar/go-iw4m/stats"
+)
+
+type StatDelta struct {
+	Stat  string  `json:"stat"`
+	A     float64 `json:"a"`
+	B     float64 `json:"b"`
+	Delta float64 `json:"delta"`
+}
+
+type LocationDelta struct {
+	Location string  `json:"location"`
+	A        float64 `json:"a"`
+	B        float64 `json:"b"`
+	Delta    float64 `json:"delta"`
+}
+
+type Comparison struct {
+	A             string          `json:"a"`
+	B             string          `json:"b"`
+	Stats         []StatDelta     `json:"stats"`
+	Locations     []LocationDelta `json:"locations"`
+	SharedWeapons []string        `json:"shared_weapons"`
+	OnlyA         []string        `json:"only_a_weapons"`
+	OnlyB         []string        `json:"only_b_weapons"`
+}
+
+type side struct {
+	advanced *stats.Advanced
+	basic    *models.ClientStats
+	err      error
+}
+
+func (p *Player) fetchSide(clientID string) side {
+	var (
+		s  side
+		wg sync.WaitGroup
+		mu sync.Mutex
+	)
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		a, err := p.AdvancedStats(clientID)
+		mu.Lock()
+		defer mu.Unlock()
+		if err != nil {
+			s.err = err
+			return
+		}
+		s.advanced = stats.FromAdvanced(a)
+	}()
+	go func() {
+		defer wg.Done()
+		b, err := p.PlayerStats(clientID)
+		mu.Lock()
+		defer mu.Unlock()
+		if err == nil {
+			s.basic = b
+		}
+	}()
+	wg.Wait()
+	return s
+}
+
+// Compare fetches both players' stats concurrently and diffs them. Deltas
+// are b - a
+func (p *Player) Compare(a, b string) (*Comparison, error) {
+	var sa, sb side
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() { defer wg.Done(); sa = p.fetchSide(a) }()
+	go func() { defer wg.Done(); sb = p.fetchSide(b) }()
+	wg.Wait()
+
+	if sa.err != nil {
+		return nil, fmt.Errorf("client %s: %w", a, sa.err)
+	}
+	if sb.err != nil {
+		return nil, fmt.Errorf("client %s: %w", b, sb.err)
+	}
+
+	c := &Comparison{A: sa.advanced.Name, B: sb.advanced.Name}
+	if c.A == "" {
+		c.A = a
+	}
+	if c.B == "" {
+		c.B = b
+	}
+
+	va, vb := sideStats(sa), sideStats(sb)
+	for _, key := range unionKeys(va, vb) {
+		c.Stats = append(c.Stats, StatDelta{Stat: key, A: va[key], B: vb[key], Delta: vb[key] - va[key]})
+	}
+
+	la, lb := locationShares(sa.advanced), locationShares(sb.advanced)
+	for _, key := range unionKeys(la, lb) {
+		c.Locations = append(c.Locations, LocationDelta{Location: key, A: la[key], B: lb[key], Delta: lb[key] - la[key]})
+	}
+
+	wa, wb := weaponSet(sa.advanced), weaponSet(sb.advanced)
+	for _, w := range unionKeys(wa, wb) {
+		_, inA := wa[w]
+		_, inB := wb[w]
+		switch {
+		case inA && inB:
+			c.SharedWeapons = append(c.SharedWeapons, w)
+		case inA:
+			c.OnlyA = append(c.OnlyA, w)
+		default:
+			c.OnlyB = append(c.OnlyB, w)
+		}
+	}
+
+	return c, nil
+}
+
+// sideStats combines the /api/stats totals with the advanced page values
+func sideStats(s side) map[string]float64 {
+	values := make(map[string]float64)
+	for k, v := range s.advanced.Stats {
+		values[k] = v
+	}
+	values["Headshot Ratio"] = s.advanced.HeadshotRatio()
+	values["Damage Per Hit"] = s.advanced.DamagePerHit()
+
+	if s.basic != nil {
+		var kills, deaths, spm, perf float64
+		for _, sv := range s.basic.Servers {
+			kills += sv.Kills.Float()
+			deaths += sv.Deaths.Float()
+			spm = max(spm, sv.ScorePerMinute.Float())
+			perf = max(perf, sv.Performance.Float())
+		}
+		values["Total Kills"] = kills
+		values["Total Deaths"] = deaths
+		if deaths > 0 {
+			values["Total KDR"] = kills / deaths
+		}
+		values["Best SPM"] = spm
+		values["Best Performance"] = perf
+	}
+	return values
+}
+
+func locationShares(a *stats.Advanced) map[string]float64 {
+	shares := make(map[string]float64)
+	for _, h := range a.Locations() {
+		shares[h.Location] = h.Percentage
+	}
+	return shares
+}
+
+// weaponSet returns the favorite weapons, i.e. the most used five
+func weaponSet(a *stats.Advanced) map[string]struct{} {
+	weapons := a.AllWeapons()
+	sort.SliceStable(weapons, func(i, j int) bool { return weapons[i].Usage > weapons[j].Usage })
+	set := make(map[string]struct{})
+	for i, w := range weapons {
+		if i == 5 {
+			break
+		}
+		set[w.Weapon] = struct{}{}
+	}
+	return set
+}
+
+func unionKeys[V any](a, b map[string]V) []string {
+	seen := make(map[string]bool)
+	var keys []string
+	for _, m := range []map[string]V{a, b} {
+		for k := range m {
+			if !seen[k] {
+				seen[k] = true
+				keys = append(keys, k)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (c *Comparison) JSON() ([]byte, error) {
+	return json.MarshalIndent(c, "", "  ")
+}
+
+// Table renders the comparison as an aligned text table
+func (c *Comparison) Table() string {
+	width := len("Stat")
+	for _, s := range c.Stats {
+		width = max(width, len(s.Stat))
+	}
+	for _, l := range c.Locations {
+		width = max(width, len(l.Location))
+	}
+
+	var b strings.Builder
+	row := func(name, a, bv, d string) {
+		fmt.Fprintf(&b, "%-*s  %12s  %12s  %12s\n", width, name, a, bv, d)
+	}
+
+	row("Stat", truncate(c.A, 12), truncate(c.B, 12), "Delta")
+	for _, s := range c.Stats {
+		row(s.Stat, formatValue(s.A), formatValue(s.B), signed(s.Delta))
+	}
+	if len(c.Locations) > 0 {
+		b.WriteString("\n")
+		row("Hit location", "", "", "")
+		for _, l := range c.Locations {
+			row(l.Location, percent(l.A), percent(l.B), signedPercent(l.Delta))
+		}
+	}
+
+	b.WriteString("\n")
+	fmt.Fprintf(&b, "Shared weapons: %s\n", listOrNone(c.SharedWeapons))
+	fmt.Fprintf(&b, "Only %s: %s\n", c.A, listOrNone(c.OnlyA))
+	fmt.Fprintf(&b, "Only %s: %s\n", c.B, listOrNone(c.OnlyB))
+	return b.String()
+}
+
+// ChatLines renders a short summary suited to in-game chat
+func (c *Comparison) ChatLines() []string {
+	lines := []string{fmt.Sprintf("%s vs %s", c.A, c.B)}
+
+	for _, key := range []string{"Total KDR", "Best SPM", "Headshot Ratio", "Damage Per Hit"} {
+		for _, s := range c.Stats {
+			if s.Stat != key {
+				continue
+			}
+			if key == "Headshot Ratio" {
+				lines = append(lines, fmt.Sprintf("%s: %s vs %s", key, percent(s.A), percent(s.B)))
+			} else {
+				lines = append(lines, fmt.Sprintf("%s: %s vs %s", key, formatValue(s.A), formatValue(s.B)))
+			}
+		}
+	}
+
+	if len(c.SharedWeapons) > 0 {
+		lines = append(lines, "Both favor: "+strings.Join(c.SharedWeapons, ", "))
+	}
+	return lines
+}
+
+func formatValue(v float64) string {
+	if v == float64(int64(v)) {
+		return fmt.Sprintf("%d", int64(v))
+	}
+	return fmt.Sprintf("%.2f", v)
+}
+
+func signed(v float64) string {
+	if v > 0 {
+		return "+" + formatValue(v)
+	}
+	return formatValue(v)
+}
+
+func percent(v float64) string { return fmt.Sprintf("%.1f%%", v*100) }
+
+func signedPercent(v float64) string {
+	if v > 0 {
+		return "+" + percent(v)
+	}
+	return percent(v)
+}
+
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n])
+}
+
+func listOrNone(items []string) string {
+	if len(items) == 0 {
+		return "none"
+	}
+	return strings.Join(items, ", ")
+}
@@ -0,0 +1,78 @@
+package player
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/Yallamaztar/go-iw4m/models"
+	"github.com/Yallamaztar/go-iw4m/utils"
+)
+
+const (
+	penaltyPage     = 50
+	maxPenaltyPages = 200
+)
+
+// Penalties returns every penalty a client has received, read page by page
+// from the Penalized meta of their profile
+func (p *Player) Penalties(clientID string) ([]models.Penalty, error) {
+	var penalties []models.Penalty
+	for offset, page := 0, 0; page < maxPenaltyPages; page++ {
+		r, err := p.Wrapper.DoRequestContext(context.Background(), fmt.Sprintf(
+			"%s/Client/Meta/%s?offset=%d&count=%d&metaFilterType=Penalized",
+			p.Wrapper.BaseURL, clientID, offset, penaltyPage))
+		if err != nil {
+			return nil, err
+		}
+		doc, err := goquery.NewDocumentFromReader(strings.NewReader(r))
+		if err != nil {
+			return nil, err
+		}
+
+		entries := doc.Find("div.profile-meta-entry")
+		penalties = append(penalties, parsePenalties(clientID, entries)...)
+		if entries.Length() < penaltyPage {
+			return penalties, nil
+		}
+		offset += entries.Length()
+	}
+	return penalties, fmt.Errorf("client %s has more than %d pages of penalties", clientID, maxPenaltyPages)
+}
+
+func parsePenalties(clientID string, entries *goquery.Selection) []models.Penalty {
+	var penalties []models.Penalty
+	entries.Each(func(i int, entry *goquery.Selection) {
+		typeTag := entry.Find("[class*='penalties-color-']").First()
+		if typeTag.Length() == 0 {
+			return
+		}
+		penaltyType := models.ParsePenaltyType(typeTag.Text())
+
+		punisher := entry.Find("a").First()
+		href, _ := punisher.Attr("href")
+
+		issued := cleanText(entry.Find("time, .profile-meta-title, .text-muted").First().Text())
+		expires := ""
+		entry.Find("span").Each(func(j int, span *goquery.Selection) {
+			text := strings.ToLower(span.Text())
+			if strings.Contains(text, "remaining") || strings.Contains(text, "expired") || strings.Contains(text, "revoked") {
+				expires = strings.Trim(cleanText(span.Text()), "()")
+			}
+		})
+
+		penalties = append(penalties, models.Penalty{
+			Type:       penaltyType,
+			OffenderID: clientID,
+			Punisher:   strings.TrimSpace(punisher.Text()),
+			PunisherID: utils.ClientIDFromLink(href),
+			Reason:     cleanText(entry.Find("colorcode").Last().Text()),
+			Issued:     issued,
+			Expires:    expires,
+			Active:     models.PenaltyActive(penaltyType, expires),
+		})
+	})
+
+	return penalties
+}
@@ -4,15 +4,18 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+	"sync"
 
 	"github.com/PuerkitoBio/goquery"
 	"github.com/Yallamaztar/go-iw4m/models"
-	"github.com/Yallamaztar/go-iw4m/server"
 	"github.com/Yallamaztar/go-iw4m/wrapper"
 )
 
 type Player struct {
 	Wrapper *wrapper.IW4MWrapper
+
+	once     sync.Once
+	resolver *Resolver
 }
 
 // Constructor to create Player from IW4MWrapper instance
@@ -20,14 +23,30 @@ func NewPlayer(w *wrapper.IW4MWrapper) *Player {
 	return &Player{Wrapper: w}
 }
 
-func (p *Player) PlayerStats(clientID string) (string, error) {
+func (p *Player) PlayerStats(clientID string) (*models.ClientStats, error) {
 	r := p.Wrapper.DoRequest(fmt.Sprintf("%s/api/stats/%s", p.Wrapper.BaseURL, clientID))
 
+	r = strings.TrimSpace(r)
 	if r == "" {
-		return "", fmt.Errorf("empty response from server")
+		return nil, fmt.Errorf("empty response from server")
+	}
+
+	stats := &models.ClientStats{ClientID: clientID}
+	if strings.HasPrefix(r, "{") {
+		var entry models.ServerStats
+		if err := json.Unmarshal([]byte(r), &entry); err != nil {
+			return nil, err
+		}
+		stats.Servers = append(stats.Servers, entry)
+	} else if err := json.Unmarshal([]byte(r), &stats.Servers); err != nil {
+		return nil, err
+	}
+
+	if len(stats.Servers) == 0 {
+		return nil, fmt.Errorf("no stats for client %s", clientID)
 	}
 
-	return r, nil
+	return stats, nil
 }
 
 func (p *Player) AdvancedStats(clientID string) (*models.AdvancedStats, error) {
@@ -116,74 +135,59 @@ func (p *Player) AdvancedStats(clientID string) (*models.AdvancedStats, error) {
 	return model, nil
 }
 
-func (p *Player) ClientInfo(clientID string) (map[string]interface{}, error) {
+func (p *Player) ClientInfo(clientID string) (*models.ClientInfo, error) {
 	r := p.Wrapper.DoRequest(fmt.Sprintf("%s/api/client/%s", p.Wrapper.BaseURL, clientID))
 	if r == "" {
 		return nil, fmt.Errorf("empty response from server")
 	}
 
-	var data map[string]any
-	if err := json.Unmarshal([]byte(r), &data); err != nil {
+	var info models.ClientInfo
+	if err := json.Unmarshal([]byte(r), &info); err != nil {
 		return nil, err
 	}
-	return data, nil
+	return &info, nil
 }
 
-// func (p *Player) GetPlayerRankFromName(playerName string) (int, error) {
-// 	clientID, err := p.GetClientIDFromName(playerName)
-// 	if err != nil {
-// 		return -1, err
-// 	}
+// Resolver returns the Player's identity resolver, created on first use
+func (p *Player) Resolver() *Resolver {
+	p.once.Do(func() {
+		if p.resolver == nil {
+			p.resolver = NewResolver(p.Wrapper, DefaultResolverTTL)
+		}
+	})
+	return p.resolver
+}
 
-// 	info, err := p.ClientInfo(clientID)
-// 	if err != nil {
-// 		return -1, err
-// 	}
+func (p *Player) GetPlayerRankFromName(playerName string) (int, error) {
+	clientID, err := p.GetClientIDFromName(playerName)
+	if err != nil {
+		return -1, err
+	}
 
-// 	level, ok := info["level"].(float64)
-// 	if !ok {
-// 		return -1, fmt.Errorf("could not parse level for player %s", playerName)
-// 	}
+	info, err := p.ClientInfo(clientID)
+	if err != nil {
+		return -1, err
+	}
 
-// 	return int(level), nil
-// }
+	return info.Level, nil
+}
 
 func (p *Player) GetXUIDFromName(playerName string) (string, error) {
-	server := server.NewServer(p.Wrapper)
-	data, _ := server.FindPlayer(playerName, "", "", "", "", "")
-	var result models.PlayerResponse
-	if err := json.Unmarshal([]byte(data), &result); err != nil {
+	id, err := p.Resolver().ResolveName(playerName)
+	if err != nil {
 		return "", err
 	}
 
-	if len(result.Clients) == 0 {
-		return "", fmt.Errorf("player %s not found", playerName)
-	}
-
-	return result.Clients[0].XUID, nil
+	return id.XUID, nil
 }
 
 func (p *Player) GetNameFromXUID(xuid string) (string, error) {
-	server := server.NewServer(p.Wrapper)
-	data, err := server.FindPlayer("", "", xuid, "", "", "")
+	id, err := p.Resolver().ResolveXUID(xuid)
 	if err != nil {
-		return "", fmt.Errorf("error finding player: %w", err)
-	}
-
-	if strings.TrimSpace(data) == "" {
-		return "", fmt.Errorf("empty response from FindPlayer")
-	}
-
-	var result models.PlayerResponse
-	if err := json.Unmarshal([]byte(data), &result); err != nil {
-		return "", fmt.Errorf("failed to parse JSON: %w\nRaw: %s", err, data)
-	}
-
-	if len(result.Clients) == 0 {
-		return "", fmt.Errorf("no player with xuid %s", xuid)
+		return "", err
 	}
 
-	return result.Clients[0].Name, nil
+	return id.Name, nil
 }
 
 func (p *Player) GetNameFromClientID(clientID string) (string, error) {
@@ -192,24 +196,18 @@ func (p *Player) GetNameFromClientID(clientID string) (string, error) {
 		return "", err
 	}
 
-	name, ok := info["name"].(string)
-	if !ok {
+	if info.Name == "" {
 		return "", fmt.Errorf("could not find name in client info")
 	}
 
-	return name, nil
+	return info.Name, nil
 }
 
-// func (p *Player) GetClientIDFromName(playerName string) (string, error) {
-// 	data := p.Wrapper.Server(p.Wrapper).FindPlayer(playerName)
-// 	var result models.PlayerResponse
-// 	if err := json.Unmarshal([]byte(data), &result); err != nil {
-// 		return "", err
-// 	}
-
-// 	if len(result.Clients) == 0 {
-// 		return "", fmt.Errorf("player %s not found", playerName)
-// 	}
+func (p *Player) GetClientIDFromName(playerName string) (string, error) {
+	id, err := p.Resolver().ResolveName(playerName)
+	if err != nil {
+		return "", err
+	}
 
-// 	return result.Clients[0].ClientID, nil
-// }
+	return id.ClientID, nil
+}
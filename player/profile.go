@@ -0,0 +1,170 @@
+package player
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/Yallamaztar/go-iw4m/models"
+)
+
+var levelColor = regexp.MustCompile(`level-color-(-?\d+)`)
+
+// Profile scrapes /Client/Profile/{clientID}. IP history is only present
+// when the logged in account is allowed to see it
+func (p *Player) Profile(clientID string) (*models.Profile, error) {
+	r := p.Wrapper.DoRequest(fmt.Sprintf("%s/Client/Profile/%s", p.Wrapper.BaseURL, clientID))
+	if r == "" {
+		return nil, fmt.Errorf("empty response from server")
+	}
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(r))
+	if err != nil {
+		return nil, err
+	}
+
+	profile := &models.Profile{ClientID: clientID}
+
+	// login and error pages have color coded text too, so only a page with
+	// the profile container counts
+	header := doc.Find("#profile_wrapper, div.profile-header").First()
+	if header.Length() == 0 {
+		return nil, fmt.Errorf("client %s: no profile on page (not found, or not logged in)", clientID)
+	}
+
+	profile.Name = strings.TrimSpace(header.Find("#profile_name colorcode, .client-name colorcode").First().Text())
+	if profile.Name == "" {
+		return nil, fmt.Errorf("client %s: profile has no name", clientID)
+	}
+
+	profile.GUID = strings.TrimSpace(header.Find("#profile_guid, .profile-guid").First().Text())
+
+	header.Find("[class*='level-color-']").EachWithBreak(func(i int, s *goquery.Selection) bool {
+		class, _ := s.Attr("class")
+		m := levelColor.FindStringSubmatch(class)
+		if m == nil {
+			return true
+		}
+		level, _ := strconv.Atoi(m[1])
+		profile.Role = models.Role(level)
+		profile.Level = strings.TrimSpace(s.Text())
+		return false
+	})
+	if profile.Level == "" {
+		profile.Level = profile.Role.String()
+	}
+
+	doc.Find("#profile_aliases .profile-alias, #profile_aliases > div").Each(func(i int, s *goquery.Selection) {
+		name := strings.TrimSpace(s.Find("colorcode").First().Text())
+		if name == "" {
+			name = strings.TrimSpace(s.Find("span").First().Text())
+		}
+		date := strings.TrimSpace(s.Find(".text-muted, time").First().Text())
+		if name != "" {
+			profile.Aliases = append(profile.Aliases, models.Alias{Name: name, Date: date})
+		}
+	})
+
+	doc.Find("#profile_ips .profile-ip, #profile_ips > div").Each(func(i int, s *goquery.Selection) {
+		address, ok := s.Find("[data-ip]").First().Attr("data-ip")
+		if !ok {
+			address = strings.TrimSpace(s.Find("a, span").First().Text())
+		}
+		date := strings.TrimSpace(s.Find(".text-muted, time").First().Text())
+		if address != "" {
+			profile.IPs = append(profile.IPs, models.IPEntry{Address: strings.TrimSpace(address), Date: date})
+		}
+	})
+
+	doc.Find("div.profile-meta-entry").Each(func(i int, s *goquery.Selection) {
+		key := cleanText(s.Find(".profile-meta-title").Text())
+		value := cleanText(s.Find(".profile-meta-value").Text())
+		if key == "" || value == "" {
+			return
+		}
+		profile.Meta = append(profile.Meta, models.StatEntry{Key: key, Value: value})
+		applyMeta(profile, key, value)
+	})
+
+	return profile, nil
+}
+
+func cleanText(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+type metaField int
+
+const (
+	metaFirstSeen metaField = iota + 1
+	metaLastSeen
+	metaPlayTime
+	metaServer
+	metaWarnings
+	metaKicks
+	metaTempBans
+	metaBans
+	metaFlags
+)
+
+// metaKeys maps whole meta titles, lower cased and without a trailing
+// colon, to the field they fill
+var metaKeys = map[string]metaField{
+	"first seen":       metaFirstSeen,
+	"first connected":  metaFirstSeen,
+	"first connection": metaFirstSeen,
+	"last seen":        metaLastSeen,
+	"last connected":   metaLastSeen,
+	"last connection":  metaLastSeen,
+	"play time":        metaPlayTime,
+	"playtime":         metaPlayTime,
+	"time played":      metaPlayTime,
+	"total play time":  metaPlayTime,
+	"connection time":  metaPlayTime,
+	"server":           metaServer,
+	"current server":   metaServer,
+	"last server":      metaServer,
+	"warnings":         metaWarnings,
+	"warns":            metaWarnings,
+	"kicks":            metaKicks,
+	"temp bans":        metaTempBans,
+	"tempbans":         metaTempBans,
+	"temporary bans":   metaTempBans,
+	"bans":             metaBans,
+	"permanent bans":   metaBans,
+	"flags":            metaFlags,
+	"times flagged":    metaFlags,
+}
+
+// applyMeta copies well known profile meta entries into their typed fields
+func applyMeta(profile *models.Profile, key, value string) {
+	field := metaKeys[strings.ToLower(strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(key), ":")))]
+	count := func() int {
+		n, mult := models.NormalizeNumber(strings.Fields(value)[0])
+		f, _ := strconv.ParseFloat(n, 64)
+		return int(f * mult)
+	}
+
+	switch field {
+	case metaFirstSeen:
+		profile.FirstSeen = value
+	case metaLastSeen:
+		profile.LastSeen = value
+	case metaPlayTime:
+		profile.PlayTime = value
+	case metaServer:
+		profile.CurrentServer = value
+	case metaWarnings:
+		profile.Penalties.Warnings = count()
+	case metaKicks:
+		profile.Penalties.Kicks = count()
+	case metaTempBans:
+		profile.Penalties.TempBans = count()
+	case metaBans:
+		profile.Penalties.Bans = count()
+	case metaFlags:
+		profile.Penalties.Flags = count()
+	}
+}
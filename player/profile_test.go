@@ -0,0 +1,37 @@
+package player
+
+import (
+	"testing"
+
+	"github.com/Yallamaztar/go-iw4m/models"
+)
+
+func TestApplyMeta(t *testing.T) {
+	var p models.Profile
+	for _, m := range [][2]string{
+		{"Last Seen", "2 hours ago"},
+		{"Last Server", "^2Nightly TDM"},
+		{"First Seen:", "1 year ago"},
+		{"Play Time", "3 days"},
+		{"Bans", "2"},
+		{"Unbans", "7"},
+		{"Temp Bans", "1,204"},
+		{"Kicks", "3 kicks"},
+		{"Ban Evasion Attempts", "9"},
+	} {
+		applyMeta(&p, m[0], m[1])
+	}
+
+	if p.LastSeen != "2 hours ago" {
+		t.Errorf("LastSeen = %q; want 2 hours ago", p.LastSeen)
+	}
+	if p.CurrentServer != "^2Nightly TDM" {
+		t.Errorf("CurrentServer = %q", p.CurrentServer)
+	}
+	if p.FirstSeen != "1 year ago" || p.PlayTime != "3 days" {
+		t.Errorf("FirstSeen, PlayTime = %q, %q", p.FirstSeen, p.PlayTime)
+	}
+	if p.Penalties.Bans != 2 || p.Penalties.TempBans != 1204 || p.Penalties.Kicks != 3 {
+		t.Errorf("penalties = %+v; want 2 bans, 1204 temp bans, 3 kicks", p.Penalties)
+	}
+}
@@ -0,0 +1,235 @@
+package player
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Yallamaztar/go-iw4m/models"
+	"github.com/Yallamaztar/go-iw4m/names"
+	"github.com/Yallamaztar/go-iw4m/server"
+	"github.com/Yallamaztar/go-iw4m/utils"
+	"github.com/Yallamaztar/go-iw4m/wrapper"
+)
+
+const DefaultResolverTTL = 5 * time.Minute
+
+var ErrNotFound = errors.New("player not found")
+
+// Identity links the three ways a client is referred to
+type Identity struct {
+	ClientID string  `json:"client_id"`
+	XUID     string  `json:"xuid"`
+	Name     string  `json:"name"`
+	Level    int     `json:"level"`
+	Score    float64 `json:"score"`
+}
+
+// AmbiguousError is returned when a name matches more than one client
+type AmbiguousError struct {
+	Query      string
+	Candidates []Identity
+}
+
+func (e *AmbiguousError) Error() string {
+	names := make([]string, 0, len(e.Candidates))
+	for _, c := range e.Candidates {
+		names = append(names, fmt.Sprintf("%s (#%s)", c.Name, c.ClientID))
+	}
+	return fmt.Sprintf("%q matches %d players: %s", e.Query, len(e.Candidates), strings.Join(names, ", "))
+}
+
+type cached struct {
+	identities []Identity
+	at         time.Time
+}
+
+// Resolver maps between names, XUIDs and client IDs, caching lookups for TTL
+type Resolver struct {
+	Server *server.Server
+	Player *Player
+	TTL    time.Duration
+
+	mu    sync.Mutex
+	cache map[string]cached
+}
+
+// Constructor to create Resolver from IW4MWrapper instance
+func NewResolver(w *wrapper.IW4MWrapper, ttl time.Duration) *Resolver {
+	if ttl <= 0 {
+		ttl = DefaultResolverTTL
+	}
+	return &Resolver{
+		Server: server.NewServer(w),
+		Player: NewPlayer(w),
+		TTL:    ttl,
+		cache:  make(map[string]cached),
+	}
+}
+
+func (r *Resolver) get(key string) ([]Identity, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	c, ok := r.cache[key]
+	if !ok || time.Since(c.at) > r.TTL {
+		return nil, false
+	}
+	return c.identities, true
+}
+
+func (r *Resolver) put(key string, identities []Identity) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cache[key] = cached{identities: identities, at: time.Now()}
+}
+
+// Forget drops every cached lookup
+func (r *Resolver) Forget() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cache = make(map[string]cached)
+}
+
+func (r *Resolver) find(opts server.FindOptions) ([]models.PlayerClientInfo, error) {
+	clients, _, err := r.Server.FindPlayers(context.Background(), opts)
+	return clients, err
+}
+
+// Candidates returns every client whose name matches, best match first.
+// Search results are cached case-insensitively like the webfront search,
+// but scored against the exact query on every call
+func (r *Resolver) Candidates(name string) ([]Identity, error) {
+	key := "name:" + strings.ToLower(name)
+	found, ok := r.get(key)
+	if !ok {
+		clients, err := r.find(server.FindOptions{Name: name})
+		if err != nil {
+			return nil, err
+		}
+
+		found = make([]Identity, 0, len(clients))
+		for _, c := range clients {
+			found = append(found, Identity{
+				ClientID: c.ClientID,
+				XUID:     c.XUID,
+				Name:     c.Name,
+				Level:    c.Level,
+			})
+		}
+		r.put(key, found)
+	}
+
+	ids := make([]Identity, len(found))
+	for i, id := range found {
+		id.Score = nameScore(name, id.Name)
+		ids[i] = id
+	}
+	sort.SliceStable(ids, func(i, j int) bool { return ids[i].Score > ids[j].Score })
+	return ids, nil
+}
+
+// ResolveName returns the single client a name refers to. An exact match
+// wins over partial ones; several equally good matches are an AmbiguousError
+func (r *Resolver) ResolveName(name string) (Identity, error) {
+	ids, err := r.Candidates(name)
+	if err != nil {
+		return Identity{}, err
+	}
+	if len(ids) == 0 {
+		return Identity{}, fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	if len(ids) == 1 {
+		return ids[0], nil
+	}
+
+	best := ids[0].Score
+	if best >= exactScore && ids[1].Score < best {
+		return ids[0], nil
+	}
+
+	tied := ids
+	if best >= exactScore {
+		tied = nil
+		for _, id := range ids {
+			if id.Score == best {
+				tied = append(tied, id)
+			}
+		}
+	}
+	return Identity{}, &AmbiguousError{Query: name, Candidates: tied}
+}
+
+func (r *Resolver) ResolveXUID(xuid string) (Identity, error) {
+	key := "xuid:" + strings.ToLower(xuid)
+	if ids, ok := r.get(key); ok {
+		return ids[0], nil
+	}
+
+	clients, err := r.find(server.FindOptions{GUID: xuid})
+	if err != nil {
+		return Identity{}, err
+	}
+	for _, c := range clients {
+		if strings.EqualFold(c.XUID, xuid) {
+			id := Identity{ClientID: c.ClientID, XUID: c.XUID, Name: c.Name, Level: c.Level, Score: 1}
+			r.put(key, []Identity{id})
+			return id, nil
+		}
+	}
+	return Identity{}, fmt.Errorf("%w: xuid %s", ErrNotFound, xuid)
+}
+
+func (r *Resolver) ResolveClientID(clientID string) (Identity, error) {
+	key := "id:" + clientID
+	if ids, ok := r.get(key); ok {
+		return ids[0], nil
+	}
+
+	info, err := r.Player.ClientInfo(clientID)
+	if err != nil {
+		return Identity{}, err
+	}
+	if info.Name == "" {
+		return Identity{}, fmt.Errorf("%w: client %s", ErrNotFound, clientID)
+	}
+
+	id := Identity{
+		ClientID: strconv.Itoa(info.ClientID),
+		XUID:     info.XUID,
+		Name:     info.Name,
+		Level:    info.Level,
+		Score:    1,
+	}
+	if info.ClientID == 0 {
+		id.ClientID = clientID
+	}
+	r.put(key, []Identity{id})
+	return id, nil
+}
+
+const exactScore = 0.9
+
+var nameMatcher = names.NewMatcher(names.Options{})
+
+// nameScore rates how well candidate matches query, from 1 (identical)
+// down to 0. Exact and case-insensitive matches rank above anything the
+// fuzzy matcher produces
+func nameScore(query, candidate string) float64 {
+	q := strings.TrimSpace(utils.StripColorCodes(query))
+	c := strings.TrimSpace(utils.StripColorCodes(candidate))
+
+	switch {
+	case q == c:
+		return 1
+	case strings.EqualFold(q, c):
+		return exactScore
+	}
+
+	score, _ := nameMatcher.Score(q, c)
+	return score * 0.85
+}
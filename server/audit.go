@@ -0,0 +1,68 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/Yallamaztar/go-iw4m/models"
+)
+
+// AuditLogPageSize is the number of entries requested per audit log page
+const AuditLogPageSize = 25
+
+// AuditLogPage returns up to AuditLogPageSize audit log entries starting at
+// offset, newest first. A response that has rows but none that can be read,
+// or that is a whole page instead of the row list, is an error rather than
+// the end of the log
+func (s *Server) AuditLogPage(ctx context.Context, offset int) (*models.AuditLogPage, error) {
+	path := fmt.Sprintf("%s/Admin/ListAuditLog?offset=%d&count=%d", s.Wrapper.BaseURL, offset, AuditLogPageSize)
+
+	r, err := s.Wrapper.DoRequestContext(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(r))
+	if err != nil {
+		return nil, err
+	}
+
+	page := &models.AuditLogPage{Offset: offset}
+	rows := doc.Find("tr.d-none.d-lg-table-row")
+	rows.Each(func(i int, tr *goquery.Selection) {
+		columns := tr.Find("td")
+		if columns.Length() < 6 {
+			return
+		}
+
+		originAnchor := columns.Eq(1).Find("a").First()
+		href, _ := originAnchor.Attr("href")
+
+		target := columns.Eq(2).Text()
+		if anchor := columns.Eq(2).Find("a").First(); anchor.Length() > 0 {
+			target = anchor.Text()
+		}
+
+		page.Entries = append(page.Entries, models.AuditLog{
+			Type:   strings.TrimSpace(columns.Eq(0).Text()),
+			Origin: strings.TrimSpace(originAnchor.Text()),
+			Href:   strings.TrimSpace(href),
+			Target: strings.TrimSpace(target),
+			Data:   strings.TrimSpace(columns.Eq(4).Text()),
+			Time:   strings.TrimSpace(columns.Eq(5).Text()),
+		})
+	})
+	if len(page.Entries) == 0 {
+		if rows.Length() > 0 {
+			return nil, fmt.Errorf("audit log: %d rows in an unknown layout", rows.Length())
+		}
+		if doc.Find("form, nav").Length() > 0 {
+			return nil, fmt.Errorf("audit log: got a page instead of the entry list; is the cookie still valid?")
+		}
+	}
+	// a short page is the last one, so pagers don't need an extra request
+	page.HasMore = rows.Length() >= AuditLogPageSize
+
+	return page, nil
+}
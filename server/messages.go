@@ -0,0 +1,69 @@
+package server
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/Yallamaztar/go-iw4m/models"
+	"github.com/Yallamaztar/go-iw4m/utils"
+)
+
+const defaultMessageCount = 50
+
+// SearchMessages wraps the webfront chat search
+func (s *Server) SearchMessages(query string, filter models.MessageFilter) ([]models.ChatMessage, error) {
+	if filter.Count <= 0 {
+		filter.Count = defaultMessageCount
+	}
+
+	q := []string{"chat"}
+	if query != "" {
+		q = append(q, fmt.Sprintf("message:%q", query))
+	}
+	if filter.ServerID != "" {
+		q = append(q, "server:"+filter.ServerID)
+	}
+	if filter.ClientID != "" {
+		q = append(q, "client:"+filter.ClientID)
+	}
+	if filter.After != "" {
+		q = append(q, "after:"+filter.After)
+	}
+	if filter.Before != "" {
+		q = append(q, "before:"+filter.Before)
+	}
+
+	r := s.Wrapper.DoRequest(fmt.Sprintf("%s/Message/FindNext?query=%s&offset=%d&count=%d",
+		s.Wrapper.BaseURL, url.QueryEscape(strings.Join(q, "|")), filter.Offset, filter.Count))
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(r))
+	if err != nil {
+		return nil, err
+	}
+
+	var messages []models.ChatMessage
+	doc.Find("tr.d-none.d-lg-table-row").Each(func(i int, tr *goquery.Selection) {
+		tds := tr.Find("td")
+		if tds.Length() < 4 {
+			return
+		}
+
+		client := tds.Eq(0).Find("a").First()
+		href, _ := client.Attr("href")
+		message := strings.TrimSpace(tds.Eq(1).Find("colorcode").Text())
+		if message == "" {
+			message = cleanText(tds.Eq(1).Text())
+		}
+
+		messages = append(messages, models.ChatMessage{
+			ClientID: utils.ClientIDFromLink(href),
+			Name:     strings.TrimSpace(client.Text()),
+			Message:  message,
+			Server:   cleanText(tds.Eq(2).Text()),
+			Time:     cleanText(tds.Eq(3).Text()),
+		})
+	})
+
+	return messages, nil
+}
@@ -0,0 +1,76 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/Yallamaztar/go-iw4m/models"
+	"github.com/Yallamaztar/go-iw4m/utils"
+)
+
+const defaultPenaltyCount = 30
+
+// Penalties returns one page of the webfront's global penalty list
+func (s *Server) Penalties(filter models.PenaltyFilter) (*models.PenaltyPage, error) {
+	if filter.Count <= 0 {
+		filter.Count = defaultPenaltyCount
+	}
+	if filter.Type == "" {
+		filter.Type = models.PenaltyAny
+	}
+
+	r := s.Wrapper.DoRequest(fmt.Sprintf("%s/Penalty/ListAsync?offset=%d&count=%d&showOnly=%s&hideAutomatedPenalties=%t",
+		s.Wrapper.BaseURL, filter.Offset, filter.Count, filter.Type, filter.HideAutomated))
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(r))
+	if err != nil {
+		return nil, err
+	}
+
+	page := &models.PenaltyPage{Offset: filter.Offset, Count: filter.Count}
+	rows := doc.Find("tr.d-none.d-lg-table-row")
+	// from every row the webfront returned, before the type filter below
+	// drops any; the next page starts at Offset+Count either way
+	page.HasMore = rows.Length() >= filter.Count
+	rows.Each(func(i int, tr *goquery.Selection) {
+		tds := tr.Find("td")
+		if tds.Length() < 5 {
+			return
+		}
+
+		offender := tds.Eq(0).Find("a").First()
+		punisher := tds.Eq(3).Find("a").First()
+		offenderHref, _ := offender.Attr("href")
+		punisherHref, _ := punisher.Attr("href")
+
+		penaltyType := models.ParsePenaltyType(tds.Eq(1).Text())
+		if filter.Type != models.PenaltyAny && penaltyType != filter.Type {
+			return
+		}
+
+		issued, expires := splitPenaltyTime(tds.Eq(4))
+
+		page.Penalties = append(page.Penalties, models.Penalty{
+			Type:       penaltyType,
+			Offender:   strings.TrimSpace(offender.Text()),
+			OffenderID: utils.ClientIDFromLink(offenderHref),
+			Punisher:   strings.TrimSpace(punisher.Text()),
+			PunisherID: utils.ClientIDFromLink(punisherHref),
+			Reason:     cleanText(tds.Eq(2).Text()),
+			Issued:     issued,
+			Expires:    expires,
+			Active:     models.PenaltyActive(penaltyType, expires),
+		})
+	})
+
+	return page, nil
+}
+
+// splitPenaltyTime separates "3 days ago (2 days remaining)" style cells
+func splitPenaltyTime(td *goquery.Selection) (string, string) {
+	text := cleanText(td.Text())
+	if i := strings.Index(text, "("); i >= 0 {
+		return strings.TrimSpace(text[:i]), strings.Trim(strings.TrimSpace(text[i:]), "()")
+	}
+	return text, ""
+}
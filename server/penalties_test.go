@@ -0,0 +1,49 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Yallamaztar/go-iw4m/models"
+	"github.com/Yallamaztar/go-iw4m/wrapper"
+)
+
+func penaltyRow(kind string) string {
+	return fmt.Sprintf(`<tr class="d-none d-lg-table-row">
+		<td><a href="/Client/Profile/1">Bob</a></td><td>%s</td><td>cheating</td>
+		<td><a href="/Client/Profile/2">Admin</a></td><td>1 day ago</td></tr>`, kind)
+}
+
+func TestPenaltiesHasMoreBeforeFilter(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// an older webfront ignoring showOnly returns mixed types
+		w.Write([]byte("<table>" + penaltyRow("Kick") + penaltyRow("Ban") + penaltyRow("Warning") + "</table>"))
+	}))
+	defer ts.Close()
+
+	s := NewServer(&wrapper.IW4MWrapper{BaseURL: ts.URL, Client: ts.Client()})
+	page, err := s.Penalties(models.PenaltyFilter{Type: models.PenaltyBan, Count: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Penalties) != 1 || page.Penalties[0].Type != models.PenaltyBan {
+		t.Fatalf("penalties = %+v; want the one ban", page.Penalties)
+	}
+	if !page.HasMore {
+		t.Error("full page filtered down to one ban reported no more pages")
+	}
+
+	page, err = s.Penalties(models.PenaltyFilter{Type: models.PenaltyBan, Count: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.HasMore {
+		t.Error("short page reported more pages")
+	}
+	if !strings.Contains(page.Penalties[0].Reason, "cheating") {
+		t.Errorf("reason = %q", page.Penalties[0].Reason)
+	}
+}
@@ -0,0 +1,66 @@
+package server
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Yallamaztar/go-iw4m/models"
+	"github.com/Yallamaztar/go-iw4m/utils"
+)
+
+const DefaultPlayerCacheTTL = 10 * time.Second
+
+// PlayerCache memoizes GetPlayers for TTL so chat handlers can look up the
+// sender's role without scraping the home page for every message
+type PlayerCache struct {
+	Server *Server
+	TTL    time.Duration
+
+	mu      sync.Mutex
+	players []models.Player
+	fetched time.Time
+}
+
+// Constructor to create PlayerCache from Server instance
+func NewPlayerCache(s *Server, ttl time.Duration) *PlayerCache {
+	if ttl <= 0 {
+		ttl = DefaultPlayerCacheTTL
+	}
+	return &PlayerCache{Server: s, TTL: ttl}
+}
+
+// Players returns the cached player list, refreshing it when stale. A failed
+// refresh keeps serving the previous list
+func (c *PlayerCache) Players() []models.Player {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if time.Since(c.fetched) > c.TTL {
+		if players, err := c.Server.GetPlayers(); err == nil {
+			c.players = players
+			c.fetched = time.Now()
+		}
+	}
+	return c.players
+}
+
+// Find returns the online player with the given name, ignoring color codes
+// and case
+func (c *PlayerCache) Find(name string) (models.Player, bool) {
+	name = strings.TrimSpace(utils.StripColorCodes(name))
+	for _, p := range c.Players() {
+		if strings.EqualFold(strings.TrimSpace(utils.StripColorCodes(p.Name)), name) {
+			return p, true
+		}
+	}
+	return models.Player{}, false
+}
+
+// Role returns the role of the named player, RoleUser when not found
+func (c *PlayerCache) Role(name string) models.Role {
+	if p, ok := c.Find(name); ok {
+		return models.PlayerRole(p.Role)
+	}
+	return models.RoleUser
+}
@@ -1,9 +1,12 @@
 package server
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"net/url"
 	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/PuerkitoBio/goquery"
@@ -263,6 +266,23 @@ func (s *Server) SendCommand(command string) string {
 	return r
 }
 
+// ExecuteCommand is SendCommand for callers that need to know whether the
+// command reached the server. The console answers with a JSON list of
+// responses; anything else (a login page, an error page) is an error
+func (s *Server) ExecuteCommand(ctx context.Context, command string) (string, error) {
+	path := fmt.Sprintf("%s/Console/Execute?serverId=%s&command=%s",
+		s.Wrapper.BaseURL, s.Wrapper.ServerID, url.QueryEscape(command))
+
+	r, err := s.Wrapper.DoRequestContext(ctx, path)
+	if err != nil {
+		return "", err
+	}
+	if !strings.HasPrefix(strings.TrimSpace(r), "[") {
+		return r, fmt.Errorf("command %q was not executed", command)
+	}
+	return r, nil
+}
+
 func (s *Server) ReadChat() ([]models.Chat, error) {
 	html := s.Wrapper.DoRequest(s.Wrapper.BaseURL)
 
@@ -297,26 +317,105 @@ func (s *Server) ReadChat() ([]models.Chat, error) {
 	return chat, nil
 }
 
-func (s *Server) FindPlayer(name, ipAddress string, guid string, level string, game string, connected string) (string, error) {
-	if name == "" && guid == "" {
-		return "", fmt.Errorf("name or guid must be provided to search for a player")
+type FindOptions struct {
+	Name      string
+	IP        string
+	GUID      string
+	Level     string
+	Game      string
+	Connected *bool
+	Offset    int
+	Count     int
+}
+
+func (o FindOptions) empty() bool {
+	return o.Name == "" && o.IP == "" && o.GUID == "" && o.Level == "" && o.Game == "" && o.Connected == nil
+}
+
+func (s *Server) findURL(o FindOptions) string {
+	q := url.Values{}
+	q.Set("clientName", o.Name)
+	q.Set("clientIP", o.IP)
+	q.Set("clientGuid", o.GUID)
+	q.Set("clientLevel", o.Level)
+	q.Set("gameName", o.Game)
+	if o.Connected != nil {
+		q.Set("clientConnected", strconv.FormatBool(*o.Connected))
+	} else {
+		q.Set("clientConnected", "")
+	}
+	if o.Offset > 0 {
+		q.Set("offset", strconv.Itoa(o.Offset))
+	}
+	if o.Count > 0 {
+		q.Set("count", strconv.Itoa(o.Count))
+	}
+	return fmt.Sprintf("%s/Client/AdvancedFind?%s", s.Wrapper.BaseURL, q.Encode())
+}
+
+// FindPlayers searches clients by any combination of name, IP, GUID, level,
+// game and connection state
+func (s *Server) FindPlayers(ctx context.Context, opts FindOptions) ([]models.PlayerClientInfo, models.Pagination, error) {
+	page := models.Pagination{Offset: opts.Offset, Count: opts.Count}
+	if opts.empty() {
+		return nil, page, fmt.Errorf("at least one search criteria must be provided")
+	}
+
+	r, err := s.Wrapper.DoRequestContext(ctx, s.findURL(opts))
+	if err != nil {
+		return nil, page, err
+	}
+	if strings.TrimSpace(r) == "" {
+		return nil, page, fmt.Errorf("empty response from server")
 	}
 
-	url := fmt.Sprintf("%s/Client/AdvancedFind?clientName=%s&clientIP=%s&clientGuid=%s&clientLevel=%s&gameName=%s&clientConnected=%s",
-		s.Wrapper.BaseURL, name, ipAddress, guid, level, game, connected)
+	var result models.PlayerResponse
+	if err := json.Unmarshal([]byte(r), &result); err != nil {
+		return nil, page, fmt.Errorf("failed to parse AdvancedFind response: %w", err)
+	}
 
-	fmt.Println(url)
+	page.Total = result.TotalFound
+	if page.Total > 0 {
+		page.HasMore = opts.Offset+len(result.Clients) < page.Total
+	} else {
+		page.HasMore = opts.Count > 0 && len(result.Clients) >= opts.Count
+	}
+	if page.Count == 0 {
+		page.Count = len(result.Clients)
+	}
+	return result.Clients, page, nil
+}
 
-	r := s.Wrapper.DoRequest(fmt.Sprintf("%s/Client/AdvancedFind?clientName=%s&clientIP=%s&clientGuid=%s&clientLevel=%s&gameName=%s&clientConnected=%s",
-		s.Wrapper.BaseURL, name, ipAddress, guid, level, game, connected))
+func (s *Server) FindPlayer(name, ipAddress string, guid string, level string, game string, connected string) (string, error) {
+	opts := FindOptions{Name: name, IP: ipAddress, GUID: guid, Level: level, Game: game}
+	if c, err := strconv.ParseBool(connected); err == nil {
+		opts.Connected = &c
+	}
+	if opts.empty() {
+		return "", fmt.Errorf("at least one search criteria must be provided")
+	}
 
-	return r, nil
+	return s.Wrapper.DoRequestContext(context.Background(), s.findURL(opts))
 }
 
 func (s *Server) GetPlayers() ([]models.Player, error) {
+	r := s.Wrapper.DoRequest(fmt.Sprintf("%s/", s.Wrapper.BaseURL))
+	return parsePlayers(r)
+}
+
+// GetPlayersContext is GetPlayers with request errors returned, so a failed
+// request can't be mistaken for an empty server
+func (s *Server) GetPlayersContext(ctx context.Context) ([]models.Player, error) {
+	r, err := s.Wrapper.DoRequestContext(ctx, fmt.Sprintf("%s/", s.Wrapper.BaseURL))
+	if err != nil {
+		return nil, err
+	}
+	return parsePlayers(r)
+}
+
+func parsePlayers(r string) ([]models.Player, error) {
 	var players []models.Player
 
-	r := s.Wrapper.DoRequest(fmt.Sprintf("%s/", s.Wrapper.BaseURL))
 	doc, err := goquery.NewDocumentFromReader(strings.NewReader(r))
 	if err != nil {
 		return nil, err
@@ -589,7 +688,7 @@ func (s *Server) Admins(role string, count int) ([]models.Admin, error) {
 
 						admins = append(admins, models.Admin{
 							Name:          name,
-							Role:          role,
+							Role:          _role,
 							Game:          game,
 							LastConnected: lastConnected,
 						})
@@ -0,0 +1,359 @@
+package spam
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/Yallamaztar/go-iw4m/commands"
+	"github.com/Yallamaztar/go-iw4m/events"
+	"github.com/Yallamaztar/go-iw4m/models"
+	"github.com/Yallamaztar/go-iw4m/names"
+	"github.com/Yallamaztar/go-iw4m/server"
+	"github.com/Yallamaztar/go-iw4m/utils"
+	"github.com/Yallamaztar/go-iw4m/wrapper"
+)
+
+type Violation string
+
+const (
+	Flood       Violation = "flood"
+	Repeat      Violation = "repeat"
+	Caps        Violation = "caps"
+	Advertising Violation = "advertising"
+)
+
+type Response struct {
+	Action  commands.Action
+	Message string
+}
+
+type Config struct {
+	// Flood: more than MaxMessages within Window
+	Window      time.Duration
+	MaxMessages int
+	// Repeat: MaxRepeats messages at least Similarity alike within RepeatWindow
+	RepeatWindow time.Duration
+	MaxRepeats   int
+	Similarity   float64
+	// Caps: messages of CapsMinLength+ letters with at least CapsRatio uppercase
+	CapsRatio     float64
+	CapsMinLength int
+	Advertising   bool
+	// AllowedHosts are never treated as advertising. An entry matches that
+	// host and its subdomains; one with a path, like your own
+	// "discord.gg/invite", only matches links under that path
+	AllowedHosts []string
+
+	Responses map[Violation]Response
+	// Cooldown after a response during which the player is not punished again
+	Cooldown time.Duration
+	// staff at or above ExemptRole are never checked
+	ExemptRole commands.Exemption
+	DryRun     bool
+}
+
+func DefaultConfig() Config {
+	return Config{
+		Window:        10 * time.Second,
+		MaxMessages:   5,
+		RepeatWindow:  30 * time.Second,
+		MaxRepeats:    3,
+		Similarity:    0.85,
+		CapsRatio:     0.7,
+		CapsMinLength: 12,
+		Advertising:   true,
+		Responses: map[Violation]Response{
+			Flood:       {Action: commands.Action{Kind: commands.ActionTell}, Message: "Slow down, you are sending messages too quickly"},
+			Repeat:      {Action: commands.Action{Kind: commands.ActionWarn}, Message: "Do not repeat messages"},
+			Caps:        {Action: commands.Action{Kind: commands.ActionTell}, Message: "Please don't use excessive caps"},
+			Advertising: {Action: commands.Action{Kind: commands.ActionKick}, Message: "Advertising is not allowed"},
+		},
+		Cooldown:   30 * time.Second,
+		ExemptRole: commands.ExemptFrom(models.RoleTrusted),
+	}
+}
+
+type Detection struct {
+	Time      time.Time     `json:"time"`
+	Player    models.Player `json:"player"`
+	Violation Violation     `json:"violation"`
+	Message   string        `json:"message"`
+	Detail    string        `json:"detail"`
+	Command   string        `json:"command"`
+	DryRun    bool          `json:"dry_run"`
+}
+
+type entry struct {
+	at   time.Time
+	text string
+}
+
+type history struct {
+	messages []entry
+	cooldown time.Time
+}
+
+type Detector struct {
+	Server      *server.Server
+	Players     *server.PlayerCache
+	Config      Config
+	OnDetection func(Detection)
+
+	mu      sync.Mutex
+	players map[string]*history
+	swept   time.Time
+}
+
+// Constructor to create Detector from IW4MWrapper instance
+func NewDetector(w *wrapper.IW4MWrapper, cfg Config) *Detector {
+	srv := server.NewServer(w)
+	return &Detector{
+		Server:  srv,
+		Players: server.NewPlayerCache(srv, 0),
+		Config:  cfg,
+		players: make(map[string]*history),
+	}
+}
+
+// Attach checks chat events published on bus at the time they were sent,
+// so replayed events are rated like live ones
+func (d *Detector) Attach(bus *events.Bus) *events.Subscription {
+	return events.Handle(bus, func(e events.ChatEvent) { d.CheckAt(e.Origin, e.Message, e.Time()) })
+}
+
+var (
+	ipAddress = regexp.MustCompile(`\b\d{1,3}(?:\.\d{1,3}){3}(?::\d{2,5})?\b`)
+	invite    = regexp.MustCompile(`(?i)(?:discord(?:app)?\s*(?:\.|dot)\s*(?:gg|com\s*/\s*invite)|discord\.me)\s*/\s*[a-z0-9-]+`)
+	link      = regexp.MustCompile(`(?i)\b(?:https?://|www\.)[^\s]+`)
+)
+
+// findIPs returns the dotted quads in message whose octets are all 255 or less
+func findIPs(message string) []string {
+	var ips []string
+	for _, m := range ipAddress.FindAllString(message, -1) {
+		host, _, _ := strings.Cut(m, ":")
+		valid := true
+		for _, octet := range strings.Split(host, ".") {
+			if n, err := strconv.Atoi(octet); err != nil || n > 255 {
+				valid = false
+				break
+			}
+		}
+		if valid {
+			ips = append(ips, m)
+		}
+	}
+	return ips
+}
+
+// inviteAddress spells an invite the way a link would, e.g.
+// "discord dot gg / abc" becomes "discord.gg/abc"
+func inviteAddress(m string) string {
+	host, path, _ := strings.Cut(strings.Join(strings.Fields(m), ""), "/")
+	return strings.ReplaceAll(strings.ToLower(host), "dot", ".") + "/" + path
+}
+
+// address splits an advertised link or IP into its host and path
+func address(s string) (string, string) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if !strings.Contains(s, "://") {
+		s = "http://" + s
+	}
+	u, err := url.Parse(s)
+	if err != nil {
+		return "", ""
+	}
+	return u.Hostname(), strings.TrimSuffix(u.Path, "/")
+}
+
+func (d *Detector) allowed(m string) bool {
+	host, path := address(m)
+	if host == "" {
+		return false
+	}
+	for _, a := range d.Config.AllowedHosts {
+		allowHost, allowPath := address(a)
+		if host != allowHost && !strings.HasSuffix(host, "."+allowHost) {
+			continue
+		}
+		if allowPath == "" || path == allowPath || strings.HasPrefix(path, allowPath+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func (d *Detector) advertising(message string) (string, bool) {
+	for _, m := range invite.FindAllString(message, -1) {
+		if !d.allowed(inviteAddress(m)) {
+			return m, true
+		}
+	}
+	for _, m := range append(link.FindAllString(message, -1), findIPs(message)...) {
+		if !d.allowed(m) {
+			return m, true
+		}
+	}
+	return "", false
+}
+
+func capsRatio(message string) (float64, int) {
+	var letters, upper int
+	for _, r := range message {
+		if unicode.IsLetter(r) {
+			letters++
+			if unicode.IsUpper(r) {
+				upper++
+			}
+		}
+	}
+	if letters == 0 {
+		return 0, 0
+	}
+	return float64(upper) / float64(letters), letters
+}
+
+// Check is CheckAt for a message sent now
+func (d *Detector) Check(origin, message string) *Detection {
+	return d.CheckAt(origin, message, time.Now())
+}
+
+// CheckAt records message sent by origin at the given time and responds to
+// the first violation it finds. It returns nil when the message is fine,
+// the sender is exempt or still within a cooldown
+func (d *Detector) CheckAt(origin, message string, at time.Time) *Detection {
+	p, ok := d.Players.Find(origin)
+	if !ok {
+		p = models.Player{Name: origin, Role: "user"}
+	}
+	if d.Config.ExemptRole.Exempt(models.PlayerRole(p.Role)) {
+		return nil
+	}
+
+	key := p.XUID
+	if key == "" {
+		key = strings.ToLower(p.Name)
+	}
+	raw := utils.StripColorCodes(message)
+	violation, detail := d.record(key, raw, names.Normalize(message, names.Options{}), at)
+	if violation == "" {
+		return nil
+	}
+
+	det := Detection{
+		Time:      at,
+		Player:    p,
+		Violation: violation,
+		Message:   message,
+		Detail:    detail,
+		DryRun:    d.Config.DryRun,
+	}
+	if resp, ok := d.Config.Responses[violation]; ok {
+		det.Command = resp.Action.Command(commands.Target(p.Name, p.XUID), resp.Message)
+	}
+	if !det.DryRun && det.Command != "" {
+		d.Server.SendCommand(det.Command)
+	}
+	if d.OnDetection != nil {
+		d.OnDetection(det)
+	}
+	return &det
+}
+
+// record adds a message to key's history and returns the violation it
+// completes, if any. Messages sent during a cooldown are not recorded, so
+// they can't count towards the next violation
+func (d *Detector) record(key, raw, text string, at time.Time) (Violation, string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	keep := max(d.Config.Window, d.Config.RepeatWindow)
+	if at.Sub(d.swept) > keep {
+		d.sweep(at, keep)
+	}
+
+	h, ok := d.players[key]
+	if !ok {
+		h = &history{}
+		d.players[key] = h
+	}
+	if at.Before(h.cooldown) {
+		return "", ""
+	}
+
+	fresh := h.messages[:0]
+	for _, m := range h.messages {
+		if at.Sub(m.at) <= keep {
+			fresh = append(fresh, m)
+		}
+	}
+	h.messages = append(fresh, entry{at: at, text: text})
+
+	violation, detail := d.detect(h, raw, text, at)
+	if violation != "" {
+		// start over so the same burst is never punished twice
+		h.messages = nil
+		h.cooldown = at.Add(d.Config.Cooldown)
+	}
+	return violation, detail
+}
+
+// sweep drops the histories of players who have been quiet for longer than
+// keep and are out of their cooldown
+func (d *Detector) sweep(now time.Time, keep time.Duration) {
+	for key, h := range d.players {
+		idle := len(h.messages) == 0 || now.Sub(h.messages[len(h.messages)-1].at) > keep
+		if idle && !now.Before(h.cooldown) {
+			delete(d.players, key)
+		}
+	}
+	d.swept = now
+}
+
+func (d *Detector) detect(h *history, raw, text string, now time.Time) (Violation, string) {
+	c := d.Config
+
+	if c.Advertising {
+		if m, ok := d.advertising(raw); ok {
+			return Advertising, m
+		}
+	}
+
+	if c.MaxMessages > 0 {
+		n := 0
+		for _, m := range h.messages {
+			if now.Sub(m.at) <= c.Window {
+				n++
+			}
+		}
+		if n > c.MaxMessages {
+			return Flood, fmt.Sprintf("%d messages in %s", n, c.Window)
+		}
+	}
+
+	if c.MaxRepeats > 0 && text != "" {
+		n := 0
+		for _, m := range h.messages {
+			if now.Sub(m.at) <= c.RepeatWindow && names.Similarity(m.text, text) >= c.Similarity {
+				n++
+			}
+		}
+		if n >= c.MaxRepeats {
+			return Repeat, fmt.Sprintf("%d similar messages in %s", n, c.RepeatWindow)
+		}
+	}
+
+	if c.CapsRatio > 0 {
+		if ratio, letters := capsRatio(raw); letters >= c.CapsMinLength && ratio >= c.CapsRatio {
+			return Caps, fmt.Sprintf("%.0f%% caps", ratio*100)
+		}
+	}
+
+	return "", ""
+}
@@ -0,0 +1,161 @@
+package spam
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAdvertising(t *testing.T) {
+	d := &Detector{Config: DefaultConfig()}
+	d.Config.AllowedHosts = []string{"discord.gg/ours", "example.org"}
+
+	tests := []struct {
+		message string
+		want    string
+	}{
+		{"3, 2, 1, 0 go!", ""},
+		{"scores 10,20,30,40", ""},
+		{"version 1.2.3 is out", ""},
+		{"999.1.1.1 lol", ""},
+		{"join 192.168.1.20:28960 now", "192.168.1.20:28960"},
+		{"connect 8.8.8.8", "8.8.8.8"},
+		{"come to discord.gg/abc123", "discord.gg/abc123"},
+		{"discord dot gg / xyz", "discord dot gg / xyz"},
+		{"our server discord.gg/ours", ""},
+		{"visit www.example.com", "www.example.com"},
+		{"https://discord.gg/ours see you", ""},
+		{"discord dot gg / ours", ""},
+		{"discord.gg/oursnot", "discord.gg/oursnot"},
+		{"http://evil.gg/?discord.gg/ours", "http://evil.gg/?discord.gg/ours"},
+		{"http://discord.gg.evil.com/ours", "http://discord.gg.evil.com/ours"},
+		{"https://forum.example.org/t/1", ""},
+		{"https://example.org.evil.net", "https://example.org.evil.net"},
+		{"www.dotexample.org", "www.dotexample.org"},
+		{"gg wp everyone", ""},
+	}
+	for _, tt := range tests {
+		got, ok := d.advertising(tt.message)
+		if ok != (tt.want != "") || got != tt.want {
+			t.Errorf("advertising(%q) = %q, %v; want %q", tt.message, got, ok, tt.want)
+		}
+	}
+}
+
+func TestCapsRatio(t *testing.T) {
+	tests := []struct {
+		message string
+		ratio   float64
+		letters int
+	}{
+		{"", 0, 0},
+		{"1234", 0, 0},
+		{"ABCD", 1, 4},
+		{"AbCd!", 0.5, 4},
+	}
+	for _, tt := range tests {
+		ratio, letters := capsRatio(tt.message)
+		if ratio != tt.ratio || letters != tt.letters {
+			t.Errorf("capsRatio(%q) = %v, %d; want %v, %d", tt.message, ratio, letters, tt.ratio, tt.letters)
+		}
+	}
+}
+
+func newTestDetector() *Detector {
+	return &Detector{Config: DefaultConfig(), players: make(map[string]*history)}
+}
+
+func TestFlood(t *testing.T) {
+	d := newTestDetector()
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	chat := []string{"hello", "gg", "nice shot", "who is hosting", "lol", "next map?", "brb", "ok", "wp", "rematch"}
+	for i, text := range chat[:5] {
+		if v, _ := d.record("42", text, text, start.Add(time.Duration(i)*time.Second)); v != "" {
+			t.Fatalf("message %d flagged %s", i+1, v)
+		}
+	}
+	if v, _ := d.record("42", chat[5], chat[5], start.Add(5*time.Second)); v != Flood {
+		t.Fatalf("sixth message in the window = %q; want flood", v)
+	}
+
+	// spread out, the same number of messages is fine
+	d = newTestDetector()
+	for i, text := range chat {
+		if v, _ := d.record("42", text, text, start.Add(time.Duration(i)*3*time.Second)); v != "" {
+			t.Fatalf("message %d flagged %s", i+1, v)
+		}
+	}
+}
+
+func TestRepeat(t *testing.T) {
+	d := newTestDetector()
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	texts := []string{"buy gold now", "buy gold now!", "buy gold now"}
+	for i, text := range texts[:2] {
+		if v, _ := d.record("42", text, text, start.Add(time.Duration(i)*5*time.Second)); v != "" {
+			t.Fatalf("message %d flagged %s", i+1, v)
+		}
+	}
+	if v, _ := d.record("42", texts[2], texts[2], start.Add(10*time.Second)); v != Repeat {
+		t.Fatalf("third repeat = %q; want repeat", v)
+	}
+
+	// repeats that fall out of the window are forgotten
+	d = newTestDetector()
+	for i, text := range texts {
+		if v, _ := d.record("42", text, text, start.Add(time.Duration(i)*time.Minute)); v != "" {
+			t.Fatalf("message %d flagged %s", i+1, v)
+		}
+	}
+}
+
+func TestCooldown(t *testing.T) {
+	d := newTestDetector()
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	at := func(s int) time.Time { return start.Add(time.Duration(s) * time.Second) }
+
+	for i := range 3 {
+		d.record("42", "spam", "spam", at(i))
+	}
+	if v := d.players["42"].cooldown; !v.Equal(at(2).Add(d.Config.Cooldown)) {
+		t.Fatalf("cooldown = %v; want %v", v, at(2).Add(d.Config.Cooldown))
+	}
+
+	// the burst goes on during the cooldown without being recorded
+	for i := 3; i < 20; i++ {
+		if v, _ := d.record("42", "spam", "spam", at(i)); v != "" {
+			t.Fatalf("message during cooldown flagged %s", v)
+		}
+	}
+	if n := len(d.players["42"].messages); n != 0 {
+		t.Fatalf("%d messages recorded during cooldown", n)
+	}
+
+	// after it, the player starts from a clean history
+	if v, _ := d.record("42", "spam", "spam", at(40)); v != "" {
+		t.Fatalf("first message after cooldown flagged %s", v)
+	}
+}
+
+func TestSweep(t *testing.T) {
+	d := newTestDetector()
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	d.record("quiet", "hi", "hi", start)
+	for i := range 3 {
+		d.record("punished", "spam", "spam", start.Add(time.Duration(i)*time.Second))
+	}
+	// the first message after the 30s repeat window sweeps
+	d.record("active", "hi", "hi", start.Add(31*time.Second))
+
+	if _, ok := d.players["quiet"]; ok {
+		t.Error("idle history was not evicted")
+	}
+	if _, ok := d.players["punished"]; !ok {
+		t.Error("history in cooldown was evicted")
+	}
+	if _, ok := d.players["active"]; !ok {
+		t.Error("active history was evicted")
+	}
+}
@@ -0,0 +1,236 @@
+package sse
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Yallamaztar/go-iw4m/events"
+)
+
+const (
+	DefaultHistory   = 256
+	DefaultKeepAlive = 15 * time.Second
+	clientBuffer     = 64
+)
+
+type entry struct {
+	id    uint64
+	event events.Event
+}
+
+type filter struct {
+	servers map[string]bool
+	types   map[events.Type]bool
+}
+
+func (f filter) match(e events.Event) bool {
+	if f.servers != nil && !f.servers[e.Server()] {
+		return false
+	}
+	if f.types != nil && !f.types[e.Type()] {
+		return false
+	}
+	return true
+}
+
+type client struct {
+	filter filter
+	ch     chan entry
+	// overflow is closed when the client falls behind; it is disconnected
+	// and resumes from history with Last-Event-ID
+	overflow chan struct{}
+}
+
+// Handler publishes bus events to HTTP clients as Server-Sent Events.
+// Clients may filter with ?server=<id>&type=<type> (comma separated or
+// repeated) and resume with the Last-Event-ID header. Event IDs carry a
+// per-process epoch, so an ID from before a restart replays the whole
+// history instead of filtering it out
+type Handler struct {
+	KeepAlive time.Duration
+
+	epoch   string
+	sub     *events.Subscription
+	mu      sync.Mutex
+	history []entry
+	size    int
+	nextID  uint64
+	clients map[*client]struct{}
+}
+
+// Constructor to create Handler subscribed to bus, keeping the last
+// history events around for Last-Event-ID resume
+func NewHandler(bus *events.Bus, history int) *Handler {
+	if history <= 0 {
+		history = DefaultHistory
+	}
+
+	h := &Handler{
+		KeepAlive: DefaultKeepAlive,
+		epoch:     strconv.FormatInt(time.Now().UnixNano(), 36),
+		size:      history,
+		clients:   make(map[*client]struct{}),
+	}
+	h.sub = bus.Subscribe(h.publish, events.SubscribeOptions{Buffer: history, Policy: events.DropOldest})
+	return h
+}
+
+// Close unsubscribes the handler from its bus
+func (h *Handler) Close() {
+	h.sub.Close()
+}
+
+func (h *Handler) publish(e events.Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.nextID++
+	en := entry{id: h.nextID, event: e}
+	h.history = append(h.history, en)
+	if len(h.history) > h.size {
+		h.history = h.history[len(h.history)-h.size:]
+	}
+
+	for c := range h.clients {
+		if !c.filter.match(e) {
+			continue
+		}
+		select {
+		case c.ch <- en:
+		default:
+			delete(h.clients, c)
+			close(c.overflow)
+		}
+	}
+}
+
+func parseFilter(r *http.Request) filter {
+	var f filter
+	q := r.URL.Query()
+
+	for _, v := range q["server"] {
+		for _, id := range strings.Split(v, ",") {
+			if id = strings.TrimSpace(id); id != "" {
+				if f.servers == nil {
+					f.servers = make(map[string]bool)
+				}
+				f.servers[id] = true
+			}
+		}
+	}
+	for _, v := range q["type"] {
+		for _, t := range strings.Split(v, ",") {
+			if t = strings.TrimSpace(t); t != "" {
+				if f.types == nil {
+					f.types = make(map[events.Type]bool)
+				}
+				f.types[events.Type(t)] = true
+			}
+		}
+	}
+	return f
+}
+
+// lastEventID returns the ID a client resumes after and whether it is
+// resuming at all. IDs from another epoch resume from the start of history
+func (h *Handler) lastEventID(r *http.Request) (uint64, bool) {
+	v := strings.TrimSpace(r.Header.Get("Last-Event-ID"))
+	if v == "" {
+		v = strings.TrimSpace(r.URL.Query().Get("lastEventId"))
+	}
+	if v == "" {
+		return 0, false
+	}
+	epoch, n, ok := strings.Cut(v, "-")
+	if !ok || epoch != h.epoch {
+		return 0, true
+	}
+	id, err := strconv.ParseUint(n, 10, 64)
+	if err != nil {
+		return 0, true
+	}
+	return id, true
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	c := &client{filter: parseFilter(r), ch: make(chan entry, clientBuffer), overflow: make(chan struct{})}
+	last, resume := h.lastEventID(r)
+
+	h.mu.Lock()
+	var backlog []entry
+	if resume {
+		for _, en := range h.history {
+			if en.id > last && c.filter.match(en.event) {
+				backlog = append(backlog, en)
+			}
+		}
+	}
+	h.clients[c] = struct{}{}
+	h.mu.Unlock()
+
+	defer func() {
+		h.mu.Lock()
+		delete(h.clients, c)
+		h.mu.Unlock()
+	}()
+
+	header := w.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+	header.Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	for _, en := range backlog {
+		if err := h.writeEvent(w, en); err != nil {
+			return
+		}
+	}
+	flusher.Flush()
+
+	keepAlive := h.KeepAlive
+	if keepAlive <= 0 {
+		keepAlive = DefaultKeepAlive
+	}
+	ticker := time.NewTicker(keepAlive)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-c.overflow:
+			return
+		case en := <-c.ch:
+			if err := h.writeEvent(w, en); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-ticker.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+func (h *Handler) writeEvent(w http.ResponseWriter, en entry) error {
+	data, err := json.Marshal(en.event)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %s-%d\nevent: %s\ndata: %s\n\n", h.epoch, en.id, en.event.Type(), data)
+	return err
+}
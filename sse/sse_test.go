@@ -0,0 +1,31 @@
+package sse
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestLastEventID(t *testing.T) {
+	h := &Handler{epoch: "k3x"}
+	tests := []struct {
+		header string
+		id     uint64
+		resume bool
+	}{
+		{"", 0, false},
+		{"k3x-42", 42, true},
+		// from before a restart: everything in history is new to the client
+		{"a1b-900", 0, true},
+		{"900", 0, true},
+		{"k3x-junk", 0, true},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest("GET", "/events", nil)
+		if tt.header != "" {
+			r.Header.Set("Last-Event-ID", tt.header)
+		}
+		if id, resume := h.lastEventID(r); id != tt.id || resume != tt.resume {
+			t.Errorf("lastEventID(%q) = %d, %v; want %d, %v", tt.header, id, resume, tt.id, tt.resume)
+		}
+	}
+}
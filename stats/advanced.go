@@ -0,0 +1,268 @@
+package stats
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Yallamaztar/go-iw4m/models"
+)
+
+type HitLocation struct {
+	Location   string  `json:"location"`
+	Hits       int     `json:"hits"`
+	Percentage float64 `json:"percentage"`
+	Damage     int     `json:"damage"`
+}
+
+func FromHitLocation(h models.HitLocation) HitLocation {
+	hits, _ := ParseInt(h.Hits)
+	pct, _ := ParsePercent(h.Percentage)
+	damage, _ := ParseInt(h.Damage)
+	return HitLocation{Location: h.Location, Hits: hits, Percentage: pct, Damage: damage}
+}
+
+func (h HitLocation) DamagePerHit() float64 {
+	return ratio(float64(h.Damage), float64(h.Hits))
+}
+
+type Weapon struct {
+	Weapon              string  `json:"weapon"`
+	FavoriteAttachments string  `json:"favorite_attachments"`
+	Kills               int     `json:"kills"`
+	Hits                int     `json:"hits"`
+	Damage              int     `json:"damage"`
+	Usage               float64 `json:"usage"`
+}
+
+func FromWeaponUsage(w models.WeaponUsage) Weapon {
+	kills, _ := ParseInt(w.Kills)
+	hits, _ := ParseInt(w.Hits)
+	damage, _ := ParseInt(w.Damage)
+	usage, _ := ParsePercent(w.Usage)
+	return Weapon{
+		Weapon:              w.Weapon,
+		FavoriteAttachments: w.FavoriteAttachments,
+		Kills:               kills,
+		Hits:                hits,
+		Damage:              damage,
+		Usage:               usage,
+	}
+}
+
+func (w Weapon) DamagePerHit() float64 { return ratio(float64(w.Damage), float64(w.Hits)) }
+func (w Weapon) KillsPerHit() float64  { return ratio(float64(w.Kills), float64(w.Hits)) }
+
+// Advanced is the numeric view of models.AdvancedStats
+type Advanced struct {
+	Name         string                   `json:"name"`
+	Link         string                   `json:"link"`
+	Stats        map[string]float64       `json:"stats"`
+	Durations    map[string]time.Duration `json:"durations"`
+	HitLocations map[string][]HitLocation `json:"hit_locations"`
+	Weapons      map[string][]Weapon      `json:"weapons"`
+}
+
+// FromAdvanced parses every display string in a. Stat entries that are
+// neither numbers nor durations are dropped
+func FromAdvanced(a *models.AdvancedStats) *Advanced {
+	v := &Advanced{
+		Name:         a.Name,
+		Link:         a.Link,
+		Stats:        make(map[string]float64),
+		Durations:    make(map[string]time.Duration),
+		HitLocations: make(map[string][]HitLocation),
+		Weapons:      make(map[string][]Weapon),
+	}
+
+	for _, entry := range a.PlayerStats {
+		value := strings.TrimSpace(entry.Value)
+		if strings.HasSuffix(value, "%") {
+			if f, err := ParsePercent(value); err == nil {
+				v.Stats[entry.Key] = f
+			}
+			continue
+		}
+		if f, err := ParseFloat(value); err == nil {
+			v.Stats[entry.Key] = f
+			continue
+		}
+		if d, err := ParseDuration(value); err == nil {
+			v.Durations[entry.Key] = d
+		}
+	}
+
+	for title, locations := range a.HitLocations {
+		for _, h := range locations {
+			v.HitLocations[title] = append(v.HitLocations[title], FromHitLocation(h))
+		}
+	}
+	for title, weapons := range a.WeaponUsages {
+		for _, w := range weapons {
+			v.Weapons[title] = append(v.Weapons[title], FromWeaponUsage(w))
+		}
+	}
+	return v
+}
+
+// Stat looks up a stat by case-insensitive key, falling back to the first
+// key that contains it
+func (a *Advanced) Stat(key string) (float64, bool) {
+	for k, v := range a.Stats {
+		if strings.EqualFold(k, key) {
+			return v, true
+		}
+	}
+	key = strings.ToLower(key)
+	for _, k := range sortedKeys(a.Stats) {
+		if strings.Contains(strings.ToLower(k), key) {
+			return a.Stats[k], true
+		}
+	}
+	return 0, false
+}
+
+// Locations merges every hit location table, summing hits and damage per
+// body part
+func (a *Advanced) Locations() []HitLocation {
+	merged := make(map[string]*HitLocation)
+	var order []string
+	for _, title := range sortedKeys(a.HitLocations) {
+		for _, h := range a.HitLocations[title] {
+			key := strings.ToLower(h.Location)
+			m, ok := merged[key]
+			if !ok {
+				m = &HitLocation{Location: h.Location}
+				merged[key] = m
+				order = append(order, key)
+			}
+			m.Hits += h.Hits
+			m.Damage += h.Damage
+		}
+	}
+
+	total := 0
+	for _, m := range merged {
+		total += m.Hits
+	}
+	locations := make([]HitLocation, 0, len(order))
+	for _, key := range order {
+		m := merged[key]
+		m.Percentage = ratio(float64(m.Hits), float64(total))
+		locations = append(locations, *m)
+	}
+	return locations
+}
+
+// AllWeapons flattens every weapon table
+func (a *Advanced) AllWeapons() []Weapon {
+	var weapons []Weapon
+	for _, title := range sortedKeys(a.Weapons) {
+		weapons = append(weapons, a.Weapons[title]...)
+	}
+	return weapons
+}
+
+func (a *Advanced) TotalHits() int {
+	total := 0
+	for _, h := range a.Locations() {
+		total += h.Hits
+	}
+	return total
+}
+
+func (a *Advanced) TotalDamage() int {
+	total := 0
+	for _, h := range a.Locations() {
+		total += h.Damage
+	}
+	return total
+}
+
+// Accuracy returns the accuracy stat as a fraction
+func (a *Advanced) Accuracy() float64 {
+	if v, ok := a.Stat("accuracy"); ok {
+		if v > 1 {
+			return v / 100
+		}
+		return v
+	}
+	return 0
+}
+
+// LocationRatio returns the share of hits on locations whose name contains
+// any of parts
+func (a *Advanced) LocationRatio(parts ...string) float64 {
+	var hits, total int
+	for _, h := range a.Locations() {
+		total += h.Hits
+		name := strings.ToLower(h.Location)
+		for _, p := range parts {
+			if strings.Contains(name, strings.ToLower(p)) {
+				hits += h.Hits
+				break
+			}
+		}
+	}
+	return ratio(float64(hits), float64(total))
+}
+
+func (a *Advanced) HeadshotRatio() float64 {
+	return a.LocationRatio("head")
+}
+
+func (a *Advanced) DamagePerHit() float64 {
+	return ratio(float64(a.TotalDamage()), float64(a.TotalHits()))
+}
+
+type WeaponMetrics struct {
+	Weapon       string  `json:"weapon"`
+	Kills        int     `json:"kills"`
+	Hits         int     `json:"hits"`
+	Usage        float64 `json:"usage"`
+	DamagePerHit float64 `json:"damage_per_hit"`
+	KillsPerHit  float64 `json:"kills_per_hit"`
+}
+
+// Metrics is the derived summary of an Advanced view
+type Metrics struct {
+	Accuracy      float64         `json:"accuracy"`
+	HeadshotRatio float64         `json:"headshot_ratio"`
+	DamagePerHit  float64         `json:"damage_per_hit"`
+	Weapons       []WeaponMetrics `json:"weapons"`
+}
+
+func (a *Advanced) Metrics() Metrics {
+	m := Metrics{
+		Accuracy:      a.Accuracy(),
+		HeadshotRatio: a.HeadshotRatio(),
+		DamagePerHit:  a.DamagePerHit(),
+	}
+	for _, w := range a.AllWeapons() {
+		m.Weapons = append(m.Weapons, WeaponMetrics{
+			Weapon:       w.Weapon,
+			Kills:        w.Kills,
+			Hits:         w.Hits,
+			Usage:        w.Usage,
+			DamagePerHit: w.DamagePerHit(),
+			KillsPerHit:  w.KillsPerHit(),
+		})
+	}
+	return m
+}
+
+func ratio(a, b float64) float64 {
+	if b == 0 {
+		return 0
+	}
+	return a / b
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
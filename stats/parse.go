@@ -0,0 +1,160 @@
+package stats
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Yallamaztar/go-iw4m/models"
+)
+
+// Parsers for the display strings used on the webfront ("1,234", "45.6%",
+// "1.2k", "3 hours"). Both "," and "." are accepted as either thousands or
+// decimal separators
+
+// ParseFloat parses a display number such as "1,234.5", "1.234,5" or "1.2k"
+func ParseFloat(s string) (float64, error) {
+	n, mult := models.NormalizeNumber(s)
+	if n == "" || n == "-" {
+		return 0, fmt.Errorf("invalid number %q", s)
+	}
+	f, err := strconv.ParseFloat(n, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid number %q", s)
+	}
+	return f * mult, nil
+}
+
+// ParseInt parses a display number and rounds it to the nearest int
+func ParseInt(s string) (int, error) {
+	f, err := ParseFloat(s)
+	if err != nil {
+		return 0, err
+	}
+	return int(math.Round(f)), nil
+}
+
+// ParsePercent parses "45.6%" (or "45,6 %") into a fraction, 0.456
+func ParsePercent(s string) (float64, error) {
+	t := strings.TrimSpace(s)
+	hasSign := strings.HasSuffix(t, "%")
+	t = strings.TrimSpace(strings.TrimSuffix(t, "%"))
+
+	f, err := ParseFloat(t)
+	if err != nil {
+		return 0, fmt.Errorf("invalid percentage %q", s)
+	}
+	if !hasSign && f <= 1 {
+		return f, nil
+	}
+	return f / 100, nil
+}
+
+var durationPart = regexp.MustCompile(`(?i)(\d+(?:[.,]\d+)?)\s*(years?|y|months?|mo|weeks?|w|days?|d|hours?|hrs?|h|minutes?|mins?|m|seconds?|secs?|s)\b`)
+
+var durationUnits = map[string]time.Duration{
+	"y":  365 * 24 * time.Hour,
+	"mo": 30 * 24 * time.Hour,
+	"w":  7 * 24 * time.Hour,
+	"d":  24 * time.Hour,
+	"h":  time.Hour,
+	"m":  time.Minute,
+	"s":  time.Second,
+}
+
+func durationUnit(u string) time.Duration {
+	u = strings.ToLower(u)
+	switch {
+	case strings.HasPrefix(u, "y"):
+		return durationUnits["y"]
+	case strings.HasPrefix(u, "mo"):
+		return durationUnits["mo"]
+	case strings.HasPrefix(u, "w"):
+		return durationUnits["w"]
+	case strings.HasPrefix(u, "d"):
+		return durationUnits["d"]
+	case strings.HasPrefix(u, "h"):
+		return durationUnits["h"]
+	case strings.HasPrefix(u, "m"):
+		return durationUnits["m"]
+	}
+	return durationUnits["s"]
+}
+
+// ParseDuration parses play times such as "3 hours 12 minutes", "1.5d",
+// "2d 4h" or a "d.hh:mm:ss" timespan
+func ParseDuration(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if strings.Contains(s, ":") {
+		return models.ParseTimeSpan(s)
+	}
+
+	matches := durationPart.FindAllStringSubmatch(s, -1)
+	if len(matches) == 0 {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+
+	var d time.Duration
+	for _, m := range matches {
+		n, err := ParseFloat(m[1])
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		d += time.Duration(n * float64(durationUnit(m[2])))
+	}
+	return d, nil
+}
+
+var relativeTime = regexp.MustCompile(`(?i)^\s*(an?|\d+(?:[.,]\d+)?)\s*(years?|months?|weeks?|days?|hours?|minutes?|mins?|seconds?|secs?)\s+ago\s*$`)
+
+var timestampLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.9999999",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"1/2/2006 3:04:05 PM",
+	"1/2/2006 3:04 PM",
+	"1/2/2006 15:04:05",
+	"02/01/2006 15:04:05",
+	"02.01.2006 15:04:05",
+	"Jan 2, 2006 3:04 PM",
+	"January 2, 2006 3:04 PM",
+	"2006-01-02",
+	"1/2/2006",
+}
+
+// ParseTimestamp parses the absolute ("3/4/2024 10:22:01 PM") and relative
+// ("5 minutes ago", "a day ago", "just now") timestamps shown on the
+// webfront. Relative ones are resolved against now
+func ParseTimestamp(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	switch strings.ToLower(s) {
+	case "now", "just now", "moments ago", "a moment ago":
+		return now, nil
+	case "yesterday":
+		return now.Add(-24 * time.Hour), nil
+	}
+
+	if m := relativeTime.FindStringSubmatch(s); m != nil {
+		n := 1.0
+		if !strings.HasPrefix(strings.ToLower(m[1]), "a") {
+			f, err := ParseFloat(m[1])
+			if err != nil {
+				return time.Time{}, err
+			}
+			n = f
+		}
+		return now.Add(-time.Duration(n * float64(durationUnit(m[2])))), nil
+	}
+
+	for _, layout := range timestampLayouts {
+		if t, err := time.ParseInLocation(layout, s, now.Location()); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid timestamp %q", s)
+}
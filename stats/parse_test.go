@@ -0,0 +1,139 @@
+package stats
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseFloat(t *testing.T) {
+	tests := []struct {
+		in   string
+		want float64
+	}{
+		{"0", 0},
+		{"42", 42},
+		{"-3.5", -3.5},
+		{"1,234", 1234},
+		{"1,234,567", 1234567},
+		{"1.234.567", 1234567},
+		{"1,234.5", 1234.5},
+		{"1.234,5", 1234.5},
+		{"1,5", 1.5},
+		{"12,34", 12.34},
+		{"1.2k", 1200},
+		{"1,2k", 1200},
+		{"3M", 3e6},
+		{" 1 234 ", 1234},
+		{"+7", 7},
+	}
+	for _, tt := range tests {
+		got, err := ParseFloat(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseFloat(%q) = %v, %v; want %v", tt.in, got, err, tt.want)
+		}
+	}
+
+	for _, in := range []string{"", "-", "abc", "1.2.x"} {
+		if _, err := ParseFloat(in); err == nil {
+			t.Errorf("ParseFloat(%q) succeeded, want error", in)
+		}
+	}
+}
+
+func TestParseInt(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int
+	}{
+		{"1,234", 1234},
+		{"2.6", 3},
+		{"1.2k", 1200},
+	}
+	for _, tt := range tests {
+		got, err := ParseInt(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseInt(%q) = %v, %v; want %v", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestParsePercent(t *testing.T) {
+	tests := []struct {
+		in   string
+		want float64
+	}{
+		{"45.5%", 0.455},
+		{"45,5 %", 0.455},
+		{"100%", 1},
+		{"0.25", 0.25},
+		{"25", 0.25},
+		{"0%", 0},
+	}
+	for _, tt := range tests {
+		got, err := ParsePercent(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("ParsePercent(%q) = %v, %v; want %v", tt.in, got, err, tt.want)
+		}
+	}
+	if _, err := ParsePercent("n/a"); err == nil {
+		t.Error("ParsePercent(\"n/a\") succeeded, want error")
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		in   string
+		want time.Duration
+	}{
+		{"3 hours", 3 * time.Hour},
+		{"3 hours 12 minutes", 3*time.Hour + 12*time.Minute},
+		{"1.5d", 36 * time.Hour},
+		{"2d 4h", 52 * time.Hour},
+		{"1 week", 7 * 24 * time.Hour},
+		{"2 months", 60 * 24 * time.Hour},
+		{"30 secs", 30 * time.Second},
+		{"1.02:03:04", 26*time.Hour + 3*time.Minute + 4*time.Second},
+		{"00:45:00", 45 * time.Minute},
+	}
+	for _, tt := range tests {
+		got, err := ParseDuration(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseDuration(%q) = %v, %v; want %v", tt.in, got, err, tt.want)
+		}
+	}
+	for _, in := range []string{"", "soon", "1:2"} {
+		if _, err := ParseDuration(in); err == nil {
+			t.Errorf("ParseDuration(%q) succeeded, want error", in)
+		}
+	}
+}
+
+func TestParseTimestamp(t *testing.T) {
+	now := time.Date(2024, 3, 4, 22, 0, 0, 0, time.UTC)
+	tests := []struct {
+		in   string
+		want time.Time
+	}{
+		{"just now", now},
+		{"yesterday", now.Add(-24 * time.Hour)},
+		{"5 minutes ago", now.Add(-5 * time.Minute)},
+		{"a day ago", now.Add(-24 * time.Hour)},
+		{"an hour ago", now.Add(-time.Hour)},
+		{"2 weeks ago", now.Add(-14 * 24 * time.Hour)},
+		{"3/4/2024 10:22:01 PM", time.Date(2024, 3, 4, 22, 22, 1, 0, time.UTC)},
+		{"2024-03-04T10:22:01Z", time.Date(2024, 3, 4, 10, 22, 1, 0, time.UTC)},
+		{"2024-03-04 10:22:01", time.Date(2024, 3, 4, 10, 22, 1, 0, time.UTC)},
+		{"2024-03-04", time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		got, err := ParseTimestamp(tt.in, now)
+		if err != nil || !got.Equal(tt.want) {
+			t.Errorf("ParseTimestamp(%q) = %v, %v; want %v", tt.in, got, err, tt.want)
+		}
+	}
+	for _, in := range []string{"", "N/A", "sometime"} {
+		if _, err := ParseTimestamp(in, now); err == nil {
+			t.Errorf("ParseTimestamp(%q) succeeded, want error", in)
+		}
+	}
+}
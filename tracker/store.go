@@ -0,0 +1,120 @@
+package tracker
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Store persists snapshots. Range must return snapshots ordered by time
+type Store interface {
+	Save(s Snapshot) error
+	Range(clientID string, from, to time.Time) ([]Snapshot, error)
+	Clients() ([]string, error)
+}
+
+type MemoryStore struct {
+	mu        sync.RWMutex
+	snapshots map[string][]Snapshot
+}
+
+// Constructor to create an empty MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{snapshots: make(map[string][]Snapshot)}
+}
+
+func (m *MemoryStore) Save(s Snapshot) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	list := append(m.snapshots[s.ClientID], s)
+	if n := len(list); n > 1 && list[n-1].Time.Before(list[n-2].Time) {
+		sort.SliceStable(list, func(i, j int) bool { return list[i].Time.Before(list[j].Time) })
+	}
+	m.snapshots[s.ClientID] = list
+	return nil
+}
+
+func (m *MemoryStore) Range(clientID string, from, to time.Time) ([]Snapshot, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var out []Snapshot
+	for _, s := range m.snapshots[clientID] {
+		if !from.IsZero() && s.Time.Before(from) {
+			continue
+		}
+		if !to.IsZero() && s.Time.After(to) {
+			continue
+		}
+		out = append(out, s)
+	}
+	return out, nil
+}
+
+func (m *MemoryStore) Clients() ([]string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	ids := make([]string, 0, len(m.snapshots))
+	for id := range m.snapshots {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// FileStore keeps snapshots in memory and appends each one to an NDJSON
+// file so history survives restarts
+type FileStore struct {
+	*MemoryStore
+	Path string
+	mu   sync.Mutex
+}
+
+// Constructor to create FileStore, loading any snapshots already in path
+func NewFileStore(path string) (*FileStore, error) {
+	fs := &FileStore{MemoryStore: NewMemoryStore(), Path: path}
+
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return fs, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 4<<20)
+	for scanner.Scan() {
+		var s Snapshot
+		if err := json.Unmarshal(scanner.Bytes(), &s); err != nil {
+			continue
+		}
+		fs.MemoryStore.Save(s)
+	}
+	return fs, scanner.Err()
+}
+
+func (fs *FileStore) Save(s Snapshot) error {
+	line, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	f, err := os.OpenFile(fs.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	return fs.MemoryStore.Save(s)
+}
@@ -0,0 +1,315 @@
+package tracker
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/Yallamaztar/go-iw4m/player"
+	"github.com/Yallamaztar/go-iw4m/server"
+	"github.com/Yallamaztar/go-iw4m/stats"
+	"github.com/Yallamaztar/go-iw4m/wrapper"
+)
+
+const (
+	DefaultInterval   = 10 * time.Minute
+	DefaultSessionGap = 30 * time.Minute
+)
+
+type WeaponTotals struct {
+	Kills int `json:"kills"`
+	Hits  int `json:"hits"`
+}
+
+// Snapshot is a player's cumulative totals at one point in time
+type Snapshot struct {
+	ClientID string    `json:"client_id"`
+	Name     string    `json:"name"`
+	Time     time.Time `json:"time"`
+	Kills    int       `json:"kills"`
+	Deaths   int       `json:"deaths"`
+	Accuracy float64   `json:"accuracy"`
+	Hits     int       `json:"hits"`
+	// Shots is derived from Hits and Accuracy, since the webfront only
+	// shows the ratio
+	Shots      int                     `json:"shots"`
+	TimePlayed time.Duration           `json:"time_played"`
+	Weapons    map[string]WeaponTotals `json:"weapons,omitempty"`
+	// Online is whether the player was on the server when it was taken
+	Online bool `json:"online"`
+}
+
+type Tracker struct {
+	Player *player.Player
+	Server *server.Server
+	Store  Store
+	// ClientIDs are always snapshotted; Online adds everyone on the server
+	ClientIDs  []string
+	Online     bool
+	Interval   time.Duration
+	SessionGap time.Duration
+	OnError    func(clientID string, err error)
+}
+
+// Constructor to create Tracker from IW4MWrapper instance
+func NewTracker(w *wrapper.IW4MWrapper, store Store) *Tracker {
+	return &Tracker{
+		Player:     player.NewPlayer(w),
+		Server:     server.NewServer(w),
+		Store:      store,
+		Interval:   DefaultInterval,
+		SessionGap: DefaultSessionGap,
+	}
+}
+
+// Snapshot fetches a client's current totals without storing them
+func (t *Tracker) Snapshot(clientID string) (Snapshot, error) {
+	s := Snapshot{ClientID: clientID, Time: time.Now()}
+
+	basic, err := t.Player.PlayerStats(clientID)
+	if err != nil {
+		return s, err
+	}
+	for _, sv := range basic.Servers {
+		s.Kills += sv.Kills.Int()
+		s.Deaths += sv.Deaths.Int()
+		s.TimePlayed += sv.TimePlayed()
+		if s.Name == "" {
+			s.Name = sv.Name
+		}
+	}
+
+	if advanced, err := t.Player.AdvancedStats(clientID); err == nil {
+		view := stats.FromAdvanced(advanced)
+		s.Accuracy = view.Accuracy()
+		s.Hits = view.TotalHits()
+		if s.Accuracy > 0 {
+			s.Shots = int(math.Round(float64(s.Hits) / s.Accuracy))
+		}
+		if view.Name != "" {
+			s.Name = view.Name
+		}
+		s.Weapons = make(map[string]WeaponTotals)
+		for _, w := range view.AllWeapons() {
+			total := s.Weapons[w.Weapon]
+			total.Kills += w.Kills
+			total.Hits += w.Hits
+			s.Weapons[w.Weapon] = total
+		}
+	}
+
+	return s, nil
+}
+
+// targets returns the clients to snapshot and which of them are online
+func (t *Tracker) targets() ([]string, map[string]bool) {
+	online := make(map[string]bool)
+	seen := make(map[string]bool)
+	var ids []string
+	for _, id := range t.ClientIDs {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	players, err := t.Server.GetPlayersContext(context.Background())
+	if err != nil && t.OnError != nil {
+		t.OnError("", err)
+	}
+	for _, p := range players {
+		// GetPlayers exposes the client ID from the profile link
+		if p.XUID == "" {
+			continue
+		}
+		online[p.XUID] = true
+		if t.Online && !seen[p.XUID] {
+			seen[p.XUID] = true
+			ids = append(ids, p.XUID)
+		}
+	}
+	return ids, online
+}
+
+// Collect snapshots every target once and saves the results
+func (t *Tracker) Collect() {
+	ids, online := t.targets()
+	for _, id := range ids {
+		s, err := t.Snapshot(id)
+		s.Online = online[id]
+		if err == nil {
+			err = t.Store.Save(s)
+		}
+		if err != nil && t.OnError != nil {
+			t.OnError(id, err)
+		}
+	}
+}
+
+// Run collects snapshots every Interval until ctx is cancelled
+func (t *Tracker) Run(ctx context.Context) error {
+	interval := t.Interval
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		t.Collect()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Delta is the change between two snapshots
+type Delta struct {
+	From   time.Time `json:"from"`
+	To     time.Time `json:"to"`
+	Kills  int       `json:"kills"`
+	Deaths int       `json:"deaths"`
+	KDR    float64   `json:"kdr"`
+	Hits   int       `json:"hits"`
+	Shots  int       `json:"shots"`
+	// Accuracy is hits over shots within the delta, 0 when no shots were
+	// recorded in it
+	Accuracy   float64                 `json:"accuracy"`
+	TimePlayed time.Duration           `json:"time_played"`
+	Weapons    map[string]WeaponTotals `json:"weapons,omitempty"`
+}
+
+func Diff(a, b Snapshot) Delta {
+	d := Delta{
+		From:       a.Time,
+		To:         b.Time,
+		Kills:      b.Kills - a.Kills,
+		Deaths:     b.Deaths - a.Deaths,
+		TimePlayed: b.TimePlayed - a.TimePlayed,
+	}
+	d.KDR = kdr(d.Kills, d.Deaths)
+	// snapshots from before shots were recorded have none to diff from
+	if a.Shots > 0 && b.Shots > a.Shots {
+		d.Hits = b.Hits - a.Hits
+		d.Shots = b.Shots - a.Shots
+		d.Accuracy = min(max(float64(d.Hits)/float64(d.Shots), 0), 1)
+	}
+
+	for name, w := range b.Weapons {
+		prev := a.Weapons[name]
+		if w.Kills == prev.Kills && w.Hits == prev.Hits {
+			continue
+		}
+		if d.Weapons == nil {
+			d.Weapons = make(map[string]WeaponTotals)
+		}
+		d.Weapons[name] = WeaponTotals{Kills: w.Kills - prev.Kills, Hits: w.Hits - prev.Hits}
+	}
+	return d
+}
+
+func kdr(kills, deaths int) float64 {
+	if deaths == 0 {
+		return float64(kills)
+	}
+	return float64(kills) / float64(deaths)
+}
+
+// Sessions returns the delta of every play session in [from, to]. A
+// snapshot belongs to a session when the player was online or their time
+// played grew since the previous one; a session also ends when no snapshot
+// arrived for SessionGap. Each session is diffed from the snapshot before
+// it, so play between snapshots is never lost
+func (t *Tracker) Sessions(clientID string, from, to time.Time) ([]Delta, error) {
+	// start earlier so the first session has the snapshot before it
+	snaps, err := t.Store.Range(clientID, time.Time{}, to)
+	if err != nil {
+		return nil, err
+	}
+
+	gap := t.SessionGap
+	if gap <= 0 {
+		gap = DefaultSessionGap
+	}
+	active := func(i int) bool {
+		return snaps[i].Online || i > 0 && snaps[i].TimePlayed > snaps[i-1].TimePlayed
+	}
+
+	var sessions []Delta
+	for i := 0; i < len(snaps); i++ {
+		if !active(i) {
+			continue
+		}
+		base := max(i-1, 0)
+		for i+1 < len(snaps) && active(i+1) && snaps[i+1].Time.Sub(snaps[i].Time) <= gap {
+			i++
+		}
+		if snaps[i].Time.Before(from) {
+			continue
+		}
+		if d := Diff(snaps[base], snaps[i]); d.Kills != 0 || d.Deaths != 0 || d.TimePlayed != 0 {
+			sessions = append(sessions, d)
+		}
+	}
+	return sessions, nil
+}
+
+// Daily returns one delta per calendar day (in loc) within [from, to]
+func (t *Tracker) Daily(clientID string, from, to time.Time, loc *time.Location) ([]Delta, error) {
+	if loc == nil {
+		loc = time.Local
+	}
+	snaps, err := t.Store.Range(clientID, time.Time{}, to)
+	if err != nil {
+		return nil, err
+	}
+
+	var days []Delta
+	var base *Snapshot
+	var last Snapshot
+	day := ""
+	for i := range snaps {
+		s := snaps[i]
+		key := s.Time.In(loc).Format("2006-01-02")
+		if key != day {
+			if base != nil && !last.Time.Before(from) {
+				days = append(days, Diff(*base, last))
+			}
+			b := last
+			if base == nil {
+				b = s
+			}
+			base = &b
+			day = key
+		}
+		last = s
+	}
+	if base != nil && !last.Time.Before(from) {
+		days = append(days, Diff(*base, last))
+	}
+	return days, nil
+}
+
+// Over returns the delta across the last window, e.g. 7*24*time.Hour
+func (t *Tracker) Over(clientID string, window time.Duration) (Delta, error) {
+	snaps, err := t.Store.Range(clientID, time.Now().Add(-window), time.Time{})
+	if err != nil {
+		return Delta{}, err
+	}
+	if len(snaps) < 2 {
+		return Delta{}, fmt.Errorf("not enough snapshots for client %s", clientID)
+	}
+	return Diff(snaps[0], snaps[len(snaps)-1]), nil
+}
+
+// KDROver is the kill/death ratio over the last window
+func (t *Tracker) KDROver(clientID string, window time.Duration) (float64, error) {
+	d, err := t.Over(clientID, window)
+	if err != nil {
+		return 0, err
+	}
+	return d.KDR, nil
+}
@@ -0,0 +1,24 @@
+package tracker
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDiffAccuracy(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b Snapshot
+		want float64
+	}{
+		// lifetime accuracy barely moves, but the session itself hit 50%
+		{"session", Snapshot{Hits: 2000, Shots: 10000, Accuracy: 0.2}, Snapshot{Hits: 2100, Shots: 10200, Accuracy: 0.2059}, 0.5},
+		{"no shots", Snapshot{Hits: 2000, Shots: 10000}, Snapshot{Hits: 2000, Shots: 10000}, 0},
+		{"old snapshot", Snapshot{Accuracy: 0.2}, Snapshot{Hits: 2100, Shots: 10200}, 0},
+	}
+	for _, tt := range tests {
+		if got := Diff(tt.a, tt.b).Accuracy; math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("%s: Diff accuracy = %v; want %v", tt.name, got, tt.want)
+		}
+	}
+}
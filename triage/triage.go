@@ -0,0 +1,247 @@
+package triage
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Yallamaztar/go-iw4m/anticheat"
+	"github.com/Yallamaztar/go-iw4m/models"
+	"github.com/Yallamaztar/go-iw4m/player"
+	"github.com/Yallamaztar/go-iw4m/server"
+	"github.com/Yallamaztar/go-iw4m/stats"
+	"github.com/Yallamaztar/go-iw4m/utils"
+	"github.com/Yallamaztar/go-iw4m/wrapper"
+)
+
+type Config struct {
+	// Window only counts reports newer than this
+	Window time.Duration
+	// Threshold is the weighted reporter score that flags a target
+	Threshold float64
+	// RoleWeights weighs a reporter by role; missing roles weigh 1
+	RoleWeights map[models.Role]float64
+	// ReporterLimit is how many reports one reporter may file in the window
+	// before each further report counts for less
+	ReporterLimit int
+	// AnomalyBoost is added to the priority per z-score point of a
+	// suspicious anomaly report
+	AnomalyBoost float64
+}
+
+func DefaultConfig() Config {
+	return Config{
+		Window:    24 * time.Hour,
+		Threshold: 3,
+		RoleWeights: map[models.Role]float64{
+			models.RoleBanned:  0,
+			models.RoleFlagged: 0.25,
+			models.RoleUser:    1,
+			models.RoleTrusted: 1.5,
+		},
+		ReporterLimit: 3,
+		AnomalyBoost:  1,
+	}
+}
+
+type Reporter struct {
+	Name   string      `json:"name"`
+	Role   models.Role `json:"role"`
+	Weight float64     `json:"weight"`
+}
+
+type Target struct {
+	Name      string            `json:"name"`
+	ClientID  string            `json:"client_id"`
+	Reports   []models.Report   `json:"reports"`
+	Reporters []Reporter        `json:"reporters"`
+	Distinct  int               `json:"distinct_reporters"`
+	Score     float64           `json:"score"`
+	Latest    time.Time         `json:"latest"`
+	Flagged   bool              `json:"flagged"`
+	Anomaly   *anticheat.Report `json:"anomaly,omitempty"`
+	Priority  float64           `json:"priority"`
+	Reasons   []string          `json:"reasons"`
+}
+
+type Triage struct {
+	Server   *server.Server
+	Players  *server.PlayerCache
+	Player   *player.Player
+	Analyzer *anticheat.Analyzer
+	Config   Config
+	// RoleOf overrides how reporter roles are looked up
+	RoleOf func(name string) models.Role
+
+	mu    sync.Mutex
+	roles map[string]models.Role
+}
+
+// Constructor to create Triage from IW4MWrapper instance. analyzer may be
+// nil to skip the anomaly check
+func NewTriage(w *wrapper.IW4MWrapper, analyzer *anticheat.Analyzer) *Triage {
+	srv := server.NewServer(w)
+	return &Triage{
+		Server:   srv,
+		Players:  server.NewPlayerCache(srv, 0),
+		Player:   player.NewPlayer(w),
+		Analyzer: analyzer,
+		Config:   DefaultConfig(),
+		roles:    make(map[string]models.Role),
+	}
+}
+
+// role looks the reporter up among online players first, then in the
+// client database, caching database answers
+func (t *Triage) role(name string) models.Role {
+	if t.RoleOf != nil {
+		return t.RoleOf(name)
+	}
+	if p, ok := t.Players.Find(name); ok {
+		return models.PlayerRole(p.Role)
+	}
+
+	key := strings.ToLower(utils.StripColorCodes(name))
+	t.mu.Lock()
+	role, ok := t.roles[key]
+	t.mu.Unlock()
+	if ok {
+		return role
+	}
+
+	role = models.RoleUser
+	if id, err := t.Player.Resolver().ResolveName(name); err == nil {
+		role = models.Role(id.Level)
+	}
+	t.mu.Lock()
+	t.roles[key] = role
+	t.mu.Unlock()
+	return role
+}
+
+// Aggregate groups reports by target and scores them. It makes no network
+// calls besides reporter role lookups
+func (t *Triage) Aggregate(reports []models.Report, now time.Time) []*Target {
+	cfg := t.Config
+
+	type parsed struct {
+		report models.Report
+		at     time.Time
+	}
+	var recent []parsed
+	filed := make(map[string]int)
+	reported := make(map[string]int)
+	for _, r := range reports {
+		at, err := stats.ParseTimestamp(r.Timestamp, now)
+		if err != nil {
+			at = now
+		}
+		if cfg.Window > 0 && now.Sub(at) > cfg.Window {
+			continue
+		}
+		recent = append(recent, parsed{r, at})
+		filed[key(r.Origin)]++
+		reported[key(r.Target)]++
+	}
+
+	targets := make(map[string]*Target)
+	seen := make(map[string]map[string]bool)
+	for _, p := range recent {
+		k := key(p.report.Target)
+		tg, ok := targets[k]
+		if !ok {
+			tg = &Target{Name: p.report.Target}
+			targets[k] = tg
+			seen[k] = make(map[string]bool)
+		}
+		tg.Reports = append(tg.Reports, p.report)
+		if p.at.After(tg.Latest) {
+			tg.Latest = p.at
+		}
+
+		origin := key(p.report.Origin)
+		if seen[k][origin] || origin == k {
+			continue
+		}
+		seen[k][origin] = true
+
+		role := t.role(p.report.Origin)
+		weight := 1.0
+		if w, ok := cfg.RoleWeights[role]; ok {
+			weight = w
+		} else if role > models.RoleTrusted {
+			weight = 2
+		}
+		// prolific reporters and reporters who are reported themselves count less
+		if n := filed[origin]; cfg.ReporterLimit > 0 && n > cfg.ReporterLimit {
+			weight *= float64(cfg.ReporterLimit) / float64(n)
+		}
+		if reported[origin] > 0 {
+			weight /= float64(1 + reported[origin])
+		}
+
+		tg.Reporters = append(tg.Reporters, Reporter{Name: p.report.Origin, Role: role, Weight: weight})
+		tg.Score += weight
+	}
+
+	list := make([]*Target, 0, len(targets))
+	for _, tg := range targets {
+		tg.Distinct = len(tg.Reporters)
+		tg.Flagged = tg.Score >= cfg.Threshold
+		tg.Priority = tg.Score
+		tg.Reasons = append(tg.Reasons, fmt.Sprintf("%d reports from %d reporters (weighted %.1f)", len(tg.Reports), tg.Distinct, tg.Score))
+		list = append(list, tg)
+	}
+	return list
+}
+
+// Queue fetches the current reports, aggregates them, runs the anomaly
+// check on flagged targets and returns targets by descending priority
+func (t *Triage) Queue() ([]*Target, error) {
+	reports, err := t.Server.Reports()
+	if err != nil {
+		return nil, err
+	}
+
+	targets := t.Aggregate(reports, time.Now())
+	for _, tg := range targets {
+		if !tg.Flagged || t.Analyzer == nil {
+			continue
+		}
+
+		if id, err := t.Player.Resolver().ResolveName(tg.Name); err == nil {
+			tg.ClientID = id.ClientID
+		} else {
+			tg.Reasons = append(tg.Reasons, "could not resolve client: "+err.Error())
+			continue
+		}
+
+		anomaly, err := t.Analyzer.Analyze(tg.ClientID)
+		if err != nil {
+			tg.Reasons = append(tg.Reasons, "anomaly check failed: "+err.Error())
+			continue
+		}
+		tg.Anomaly = anomaly
+		if anomaly.Suspicious {
+			tg.Priority += anomaly.Score * t.Config.AnomalyBoost
+			tg.Reasons = append(tg.Reasons, anomaly.Reasons...)
+		}
+	}
+
+	sort.SliceStable(targets, func(i, j int) bool {
+		if targets[i].Flagged != targets[j].Flagged {
+			return targets[i].Flagged
+		}
+		if targets[i].Priority != targets[j].Priority {
+			return targets[i].Priority > targets[j].Priority
+		}
+		return targets[i].Latest.After(targets[j].Latest)
+	})
+	return targets, nil
+}
+
+func key(name string) string {
+	return strings.ToLower(strings.TrimSpace(utils.StripColorCodes(name)))
+}
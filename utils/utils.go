@@ -1,6 +1,9 @@
 package utils
 
 import (
+	"regexp"
+	"strings"
+
 	"github.com/Yallamaztar/go-iw4m/wrapper"
 )
 
@@ -12,6 +15,30 @@ func NewUtils(w *wrapper.IW4MWrapper) *Utils {
 	return &Utils{Wrapper: w}
 }
 
+var colorCode = regexp.MustCompile(`\^[0-9:;]`)
+
+// StripColorCodes removes IW4M ^0-^9, ^: and ^; color codes from text
+func StripColorCodes(text string) string {
+	return colorCode.ReplaceAllString(text, "")
+}
+
+// ClientIDFromLink extracts the client ID from links such as
+// /Client/Profile/{id} or /clientstatistics/{id}/advanced
+func ClientIDFromLink(href string) string {
+	href = strings.TrimSpace(href)
+	if i := strings.IndexAny(href, "?#"); i >= 0 {
+		href = href[:i]
+	}
+
+	parts := strings.Split(strings.Trim(href, "/"), "/")
+	for i := len(parts) - 1; i >= 0; i-- {
+		if parts[i] != "" && strings.Trim(parts[i], "0123456789") == "" {
+			return parts[i]
+		}
+	}
+	return parts[len(parts)-1]
+}
+
 // func (u *Utils) DoesRoleExists(role string) string {
 // 	server := NewServer(u.Wrapper)
 // }
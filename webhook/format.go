@@ -0,0 +1,153 @@
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/Yallamaztar/go-iw4m/events"
+	"github.com/Yallamaztar/go-iw4m/utils"
+)
+
+// Formatter turns an event into the JSON body POSTed to a webhook
+type Formatter interface {
+	Format(e events.Event) ([]byte, error)
+}
+
+// JSONFormatter posts {"type": ..., "event": ...}
+type JSONFormatter struct{}
+
+func (JSONFormatter) Format(e events.Event) ([]byte, error) {
+	return json.Marshal(struct {
+		Type  events.Type  `json:"type"`
+		Event events.Event `json:"event"`
+	}{e.Type(), e})
+}
+
+type discordField struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline"`
+}
+
+type discordEmbed struct {
+	Title       string         `json:"title"`
+	Description string         `json:"description,omitempty"`
+	Color       int            `json:"color"`
+	Fields      []discordField `json:"fields,omitempty"`
+	Timestamp   string         `json:"timestamp"`
+}
+
+type discordPayload struct {
+	Username  string         `json:"username,omitempty"`
+	AvatarURL string         `json:"avatar_url,omitempty"`
+	Embeds    []discordEmbed `json:"embeds"`
+}
+
+var defaultColors = map[events.Type]int{
+	events.TypeChat:      0x95a5a6,
+	events.TypeJoin:      0x2ecc71,
+	events.TypeLeave:     0xe67e22,
+	events.TypeMapChange: 0x3498db,
+	events.TypeReport:    0xf1c40f,
+	events.TypeAudit:     0xe74c3c,
+}
+
+// DiscordFormatter renders events as a Discord-compatible embed
+type DiscordFormatter struct {
+	Username  string
+	AvatarURL string
+	Colors    map[events.Type]int
+}
+
+func (f DiscordFormatter) Format(e events.Event) ([]byte, error) {
+	embed := discordEmbed{
+		Color:     defaultColors[e.Type()],
+		Timestamp: e.Time().UTC().Format(time.RFC3339),
+	}
+	if c, ok := f.Colors[e.Type()]; ok {
+		embed.Color = c
+	}
+
+	field := func(name, value string) {
+		value = utils.StripColorCodes(strings.TrimSpace(value))
+		if value == "" {
+			return
+		}
+		embed.Fields = append(embed.Fields, discordField{Name: name, Value: value, Inline: true})
+	}
+
+	switch ev := e.(type) {
+	case events.ChatEvent:
+		embed.Title = utils.StripColorCodes(ev.Origin)
+		embed.Description = utils.StripColorCodes(ev.Message)
+	case events.JoinEvent:
+		embed.Title = "Player joined"
+		field("Name", ev.Player.Name)
+		field("Role", ev.Player.Role)
+	case events.LeaveEvent:
+		embed.Title = "Player left"
+		field("Name", ev.Player.Name)
+		field("Role", ev.Player.Role)
+	case events.MapChangeEvent:
+		embed.Title = "Map changed"
+		field("Previous", ev.Previous)
+		field("Current", ev.Current)
+	case events.ReportEvent:
+		embed.Title = "New report"
+		field("Target", ev.Report.Target)
+		field("Reported by", ev.Report.Origin)
+		field("Reason", ev.Report.Reason)
+	case events.AuditEvent:
+		embed.Title = utils.StripColorCodes(ev.Entry.Type)
+		field("Origin", ev.Entry.Origin)
+		field("Target", ev.Entry.Target)
+		field("Data", ev.Entry.Data)
+	default:
+		embed.Title = string(e.Type())
+	}
+	field("Server", e.Server())
+
+	return json.Marshal(discordPayload{
+		Username:  f.Username,
+		AvatarURL: f.AvatarURL,
+		Embeds:    []discordEmbed{embed},
+	})
+}
+
+// TemplateFormatter renders a user-defined text/template. The template is
+// executed with the event as dot and has access to strip (color codes),
+// json (marshal a value) and type (event type) functions
+type TemplateFormatter struct {
+	tmpl *template.Template
+}
+
+// Constructor to create TemplateFormatter from template text
+func NewTemplateFormatter(text string) (*TemplateFormatter, error) {
+	tmpl, err := template.New("webhook").Funcs(template.FuncMap{
+		"strip": utils.StripColorCodes,
+		"json": func(v any) (string, error) {
+			b, err := json.Marshal(v)
+			return string(b), err
+		},
+		"type": func(e events.Event) string { return string(e.Type()) },
+	}).Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	return &TemplateFormatter{tmpl: tmpl}, nil
+}
+
+func (f *TemplateFormatter) Format(e events.Event) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := f.tmpl.Execute(&buf, e); err != nil {
+		return nil, err
+	}
+	if !json.Valid(buf.Bytes()) {
+		return nil, fmt.Errorf("template output is not valid JSON")
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,293 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Yallamaztar/go-iw4m/events"
+)
+
+const (
+	DefaultRetries = 3
+	DefaultBackoff = time.Second
+	queueSize      = 128
+)
+
+// Target is a single webhook URL and the events it should receive
+type Target struct {
+	URL       string
+	Types     []events.Type
+	Filter    func(events.Event) bool
+	Formatter Formatter
+	// RateLimit is the minimum delay between two posts to URL
+	RateLimit time.Duration
+}
+
+func (t Target) wants(e events.Event) bool {
+	if len(t.Types) > 0 {
+		found := false
+		for _, typ := range t.Types {
+			if typ == e.Type() {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return t.Filter == nil || t.Filter(e)
+}
+
+type job struct {
+	target  *Target
+	payload []byte
+}
+
+type Dispatcher struct {
+	Client  *http.Client
+	Targets []Target
+	Retries int
+	Backoff time.Duration
+	// DeadLetter is a file that undeliverable payloads are appended to as NDJSON
+	DeadLetter string
+	OnError    func(url string, err error)
+
+	mu     sync.Mutex
+	dlMu   sync.Mutex
+	queues map[string]chan job
+	wg     sync.WaitGroup
+}
+
+type deadLetter struct {
+	Time    time.Time       `json:"time"`
+	URL     string          `json:"url"`
+	Error   string          `json:"error"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+// Constructor to create Dispatcher for the given targets
+func NewDispatcher(targets ...Target) *Dispatcher {
+	return &Dispatcher{
+		Client:  &http.Client{Timeout: 10 * time.Second},
+		Targets: targets,
+		Retries: DefaultRetries,
+		Backoff: DefaultBackoff,
+	}
+}
+
+var errNotRunning = errors.New("dispatcher is not running")
+
+// Run subscribes to bus and delivers events until ctx is cancelled. On
+// shutdown the events still buffered are queued, and everything left in
+// the queues is dead-lettered
+func (d *Dispatcher) Run(ctx context.Context, bus *events.Bus) error {
+	// workers outlive ctx until the subscription has been flushed into
+	// their queues
+	wctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancel()
+
+	d.mu.Lock()
+	d.queues = make(map[string]chan job)
+	for i := range d.Targets {
+		url := d.Targets[i].URL
+		if _, ok := d.queues[url]; ok {
+			continue
+		}
+		q := make(chan job, queueSize)
+		d.queues[url] = q
+		d.wg.Add(1)
+		go d.worker(wctx, q, d.Targets[i].RateLimit)
+	}
+	d.mu.Unlock()
+
+	sub := bus.Subscribe(func(e events.Event) { d.Dispatch(e) }, events.SubscribeOptions{Buffer: queueSize})
+	<-ctx.Done()
+	sub.Close()
+	sub.Wait()
+
+	// later Dispatch calls fail instead of queueing behind a drained worker
+	d.mu.Lock()
+	d.queues = nil
+	d.mu.Unlock()
+
+	cancel()
+	d.wg.Wait()
+	return ctx.Err()
+}
+
+// Dispatch formats e for every matching target and queues it for delivery.
+// Outside Run the payloads go straight to OnError and the dead-letter file
+func (d *Dispatcher) Dispatch(e events.Event) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for i := range d.Targets {
+		t := &d.Targets[i]
+		if !t.wants(e) {
+			continue
+		}
+
+		formatter := t.Formatter
+		if formatter == nil {
+			formatter = JSONFormatter{}
+		}
+		payload, err := formatter.Format(e)
+		if err != nil {
+			d.fail(t.URL, payload, fmt.Errorf("format %s event: %w", e.Type(), err))
+			continue
+		}
+
+		q, ok := d.queues[t.URL]
+		if !ok {
+			d.fail(t.URL, payload, errNotRunning)
+			continue
+		}
+		select {
+		case q <- job{target: t, payload: payload}:
+		default:
+			d.fail(t.URL, payload, fmt.Errorf("queue full"))
+		}
+	}
+}
+
+func (d *Dispatcher) worker(ctx context.Context, q chan job, rate time.Duration) {
+	defer d.wg.Done()
+
+	var last time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			d.drain(q, ctx.Err())
+			return
+		case j := <-q:
+			if wait := rate - time.Since(last); rate > 0 && wait > 0 {
+				select {
+				case <-ctx.Done():
+					d.fail(j.target.URL, j.payload, ctx.Err())
+					d.drain(q, ctx.Err())
+					return
+				case <-time.After(wait):
+				}
+			}
+			if err := d.deliver(ctx, j); err != nil {
+				d.fail(j.target.URL, j.payload, err)
+			}
+			last = time.Now()
+		}
+	}
+}
+
+// drain dead-letters whatever is still queued when the dispatcher stops
+func (d *Dispatcher) drain(q chan job, err error) {
+	for {
+		select {
+		case j := <-q:
+			d.fail(j.target.URL, j.payload, fmt.Errorf("not delivered before shutdown: %w", err))
+		default:
+			return
+		}
+	}
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, j job) error {
+	backoff := d.Backoff
+	if backoff <= 0 {
+		backoff = DefaultBackoff
+	}
+
+	var err error
+	for attempt := 0; attempt <= d.Retries; attempt++ {
+		var retryAfter time.Duration
+		retryAfter, err = d.post(ctx, j.target.URL, j.payload)
+		if err == nil {
+			return nil
+		}
+		if retryAfter < 0 || attempt == d.Retries {
+			return err
+		}
+
+		wait := backoff << attempt
+		if retryAfter > wait {
+			wait = retryAfter
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(wait):
+		}
+	}
+	return err
+}
+
+// post returns a negative retry delay when the request must not be retried
+func (d *Dispatcher) post(ctx context.Context, url string, payload []byte) (time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(payload))
+	if err != nil {
+		return -1, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	r, err := d.Client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer r.Body.Close()
+	io.Copy(io.Discard, r.Body)
+
+	switch {
+	case r.StatusCode < 300:
+		return 0, nil
+	case r.StatusCode == http.StatusTooManyRequests:
+		secs, _ := strconv.ParseFloat(r.Header.Get("Retry-After"), 64)
+		return time.Duration(secs * float64(time.Second)), fmt.Errorf("rate limited: %s", r.Status)
+	case r.StatusCode >= 500:
+		return 0, fmt.Errorf("server error: %s", r.Status)
+	default:
+		return -1, fmt.Errorf("rejected: %s", r.Status)
+	}
+}
+
+func (d *Dispatcher) fail(url string, payload []byte, err error) {
+	if d.OnError != nil {
+		d.OnError(url, err)
+	}
+	if d.DeadLetter == "" {
+		return
+	}
+
+	if werr := d.writeDeadLetter(url, payload, err); werr != nil && d.OnError != nil {
+		d.OnError(url, fmt.Errorf("dead letter: %w", werr))
+	}
+}
+
+func (d *Dispatcher) writeDeadLetter(url string, payload []byte, err error) error {
+	if !json.Valid(payload) {
+		payload, _ = json.Marshal(string(payload))
+	}
+	line, merr := json.Marshal(deadLetter{Time: time.Now(), URL: url, Error: err.Error(), Payload: payload})
+	if merr != nil {
+		return merr
+	}
+
+	d.dlMu.Lock()
+	defer d.dlMu.Unlock()
+	f, err := os.OpenFile(d.DeadLetter, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,112 @@
+package webhook
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Yallamaztar/go-iw4m/events"
+	"github.com/Yallamaztar/go-iw4m/models"
+)
+
+func deadLetters(t *testing.T, path string) []string {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	return lines
+}
+
+func TestDispatchBeforeRun(t *testing.T) {
+	d := NewDispatcher(Target{URL: "http://127.0.0.1:1/hook"})
+	d.DeadLetter = filepath.Join(t.TempDir(), "dead.ndjson")
+	var errs []error
+	d.OnError = func(_ string, err error) { errs = append(errs, err) }
+
+	d.Dispatch(events.ChatEvent{Chat: models.Chat{Origin: "Bob", Message: "hi"}})
+
+	if len(errs) != 1 || errs[0] != errNotRunning {
+		t.Errorf("errors = %v; want %v", errs, errNotRunning)
+	}
+	if lines := deadLetters(t, d.DeadLetter); len(lines) != 1 || !strings.Contains(lines[0], `"hi"`) {
+		t.Errorf("dead letters = %q; want the chat payload", lines)
+	}
+}
+
+func TestDeadLetterWriteError(t *testing.T) {
+	d := NewDispatcher(Target{URL: "http://127.0.0.1:1/hook"})
+	d.DeadLetter = filepath.Join(t.TempDir(), "missing", "dead.ndjson")
+	var errs []string
+	d.OnError = func(_ string, err error) { errs = append(errs, err.Error()) }
+
+	d.Dispatch(events.ChatEvent{})
+
+	if len(errs) != 2 || !strings.HasPrefix(errs[1], "dead letter:") {
+		t.Errorf("errors = %q; want the dispatch error and the dead-letter write error", errs)
+	}
+}
+
+func TestShutdownDeadLetters(t *testing.T) {
+	received := make(chan struct{}, 1)
+	stop := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case received <- struct{}{}:
+		default:
+		}
+		// never answer, so every event is still pending at shutdown
+		select {
+		case <-r.Context().Done():
+		case <-stop:
+		}
+	}))
+	defer ts.Close()
+	defer close(stop)
+
+	var seen atomic.Int32
+	d := NewDispatcher(Target{URL: ts.URL, Filter: func(events.Event) bool {
+		seen.Add(1)
+		return true
+	}})
+	d.DeadLetter = filepath.Join(t.TempDir(), "dead.ndjson")
+
+	bus := events.NewBus()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- d.Run(ctx, bus) }()
+
+	// publish until the first event is in flight, then queue a few more
+	chat := events.ChatEvent{Chat: models.Chat{Origin: "Bob", Message: "hi"}}
+	for waiting := true; waiting; {
+		bus.Publish(chat)
+		select {
+		case <-received:
+			waiting = false
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	for range 5 {
+		bus.Publish(chat)
+	}
+	cancel()
+	<-done
+
+	if lines, n := deadLetters(t, d.DeadLetter), int(seen.Load()); len(lines) != n || n < 6 {
+		t.Errorf("%d dead letters for %d dispatched events; want every one", len(lines), n)
+	}
+}
@@ -1,6 +1,9 @@
 package wrapper
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"io"
 	"net/http"
 )
@@ -12,16 +15,51 @@ type IW4MWrapper struct {
 	Client   *http.Client
 }
 
+// StatusError is returned by DoRequestContext when the webfront answers
+// with a 4xx or 5xx status
+type StatusError struct {
+	Path   string
+	Status string
+	Code   int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Path, e.Status)
+}
+
+// DoRequest returns the response body whatever the status, or the error
+// text when the request fails
 func (w *IW4MWrapper) DoRequest(path string) string {
-	req, _ := http.NewRequest("GET", path, nil)
+	body, err := w.DoRequestContext(context.Background(), path)
+	var status *StatusError
+	if err != nil && !errors.As(err, &status) {
+		return err.Error()
+	}
+	return body
+}
+
+// DoRequestContext is DoRequest with cancellation and errors returned
+// instead of being folded into the body. An error status is returned as a
+// *StatusError along with the body
+func (w *IW4MWrapper) DoRequestContext(ctx context.Context, path string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", path, nil)
+	if err != nil {
+		return "", err
+	}
 	req.Header.Set("Cookie", w.Cookie)
 
 	r, err := w.Client.Do(req)
 	if err != nil {
-		return err.Error()
+		return "", err
 	}
 	defer r.Body.Close()
 
-	body, _ := io.ReadAll(r.Body)
-	return string(body)
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return "", err
+	}
+	if r.StatusCode >= 400 {
+		return string(body), &StatusError{Path: path, Status: r.Status, Code: r.StatusCode}
+	}
+	return string(body), nil
 }